@@ -0,0 +1,96 @@
+// Package address normalizes the brazilian addresses and looks up the location of the CEPs.
+package address
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// cepData is the bundled dataset of CEP ranges, see cep.csv
+//
+//go:embed cep.csv
+var cepData string
+
+// Location is the city and the state (UF) of a CEP,
+// the city is empty when the dataset only knows the state.
+type Location struct {
+	City  string
+	State string
+}
+
+// cepRange is a range of CEPs, inclusive, of a location
+type cepRange struct {
+	first, last int
+	location    Location
+}
+
+var (
+	cepOnce   sync.Once
+	cepRanges []cepRange
+)
+
+// loadRanges parses the bundled dataset, it panics on a malformed one since it is built in the binary
+func loadRanges() {
+	r := csv.NewReader(strings.NewReader(cepData))
+	r.Comment = '#'
+	records, err := r.ReadAll()
+	if err != nil {
+		panic("address: invalid CEP dataset: " + err.Error())
+	}
+	for _, record := range records {
+		first, err1 := strconv.Atoi(record[0])
+		last, err2 := strconv.Atoi(record[1])
+		if err1 != nil || err2 != nil || len(record) != 4 {
+			panic("address: invalid CEP range " + strings.Join(record, ","))
+		}
+		cepRanges = append(cepRanges, cepRange{first, last, Location{City: record[3], State: record[2]}})
+	}
+}
+
+// Lookup returns the location of the CEP, formatted or not, from the most specific range of the dataset.
+// It returns false when the CEP is malformed or out of the known ranges.
+func Lookup(cep string) (Location, bool) {
+	digits := onlyDigits(cep)
+	if len(digits) != 8 {
+		return Location{}, false
+	}
+	n, _ := strconv.Atoi(digits)
+
+	cepOnce.Do(loadRanges)
+	var found *cepRange
+	for i, r := range cepRanges {
+		if n < r.first || n > r.last {
+			continue
+		}
+		if found == nil || r.last-r.first < found.last-found.first {
+			found = &cepRanges[i]
+		}
+	}
+	if found == nil {
+		return Location{}, false
+	}
+	return found.location, true
+}
+
+// FormatCEP formats the 8 digits of the CEP as 00000-000,
+// other values are returned trimmed, to fail the validation.
+func FormatCEP(cep string) string {
+	digits := onlyDigits(cep)
+	if len(digits) != 8 {
+		return strings.TrimSpace(cep)
+	}
+	return digits[:5] + "-" + digits[5:]
+}
+
+// onlyDigits removes everything but the digits of s
+func onlyDigits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < '0' || r > '9' {
+			return -1
+		}
+		return r
+	}, s)
+}
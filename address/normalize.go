@@ -0,0 +1,186 @@
+package address
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/lucasfloriani/go-mongo/model"
+	"github.com/lucasfloriani/go-mongo/search"
+)
+
+// DefaultCountry is the country of the addresses without one
+const DefaultCountry = "Brasil"
+
+// streetTypes expands the abbreviated type of the street, the first word of it
+var streetTypes = map[string]string{
+	"r":    "Rua",
+	"av":   "Avenida",
+	"avda": "Avenida",
+	"al":   "Alameda",
+	"tv":   "Travessa",
+	"trav": "Travessa",
+	"pc":   "Praça",
+	"pca":  "Praça",
+	"pça":  "Praça",
+	"rod":  "Rodovia",
+	"est":  "Estrada",
+	"estr": "Estrada",
+	"lg":   "Largo",
+	"lgo":  "Largo",
+}
+
+// titles expands the abbreviations found anywhere in the names of streets, neighborhoods and cities
+var titles = map[string]string{
+	"dr":   "Doutor",
+	"dra":  "Doutora",
+	"prof": "Professor",
+	"pres": "Presidente",
+	"sen":  "Senador",
+	"dep":  "Deputado",
+	"gov":  "Governador",
+	"cel":  "Coronel",
+	"gen":  "General",
+	"mal":  "Marechal",
+	"cap":  "Capitão",
+	"sta":  "Santa",
+	"sto":  "Santo",
+	"jd":   "Jardim",
+	"jar":  "Jardim",
+	"vl":   "Vila",
+	"pq":   "Parque",
+}
+
+// complements expands the abbreviations found anywhere in the complement
+var complements = map[string]string{
+	"ap":   "Apto",
+	"apt":  "Apto",
+	"apto": "Apto",
+	"bl":   "Bloco",
+	"bloc": "Bloco",
+	"cs":   "Casa",
+	"sl":   "Sala",
+	"lj":   "Loja",
+	"cj":   "Conjunto",
+	"conj": "Conjunto",
+	"and":  "Andar",
+}
+
+// particles are kept in lower case, unless they start the name
+var particles = map[string]bool{"de": true, "da": true, "do": true, "das": true, "dos": true, "e": true}
+
+// romanNumeral matches the Roman numerals up to 39, as in "Rua XV de Novembro". The ones with
+// C, D, L and M are left out, since they clash with names like "Di" and "Li"
+var romanNumeral = regexp.MustCompile(`(?i)^X{0,3}(IX|IV|V?I{0,3})$`)
+
+// countries are the spellings of DefaultCountry
+var countries = map[string]bool{"br": true, "bra": true, "brasil": true, "brazil": true}
+
+// states maps the names of the states, normalized by search.Normalize, to their UF
+var states = map[string]string{
+	"acre": "AC", "alagoas": "AL", "amapa": "AP", "amazonas": "AM", "bahia": "BA", "ceara": "CE",
+	"distrito federal": "DF", "espirito santo": "ES", "goias": "GO", "maranhao": "MA",
+	"mato grosso": "MT", "mato grosso do sul": "MS", "minas gerais": "MG", "para": "PA",
+	"paraiba": "PB", "parana": "PR", "pernambuco": "PE", "piaui": "PI", "rio de janeiro": "RJ",
+	"rio grande do norte": "RN", "rio grande do sul": "RS", "rondonia": "RO", "roraima": "RR",
+	"santa catarina": "SC", "sao paulo": "SP", "sergipe": "SE", "tocantins": "TO",
+}
+
+// Normalize fixes the casing, spacing and abbreviations of the address fields, formats the CEP,
+// converts the name of the state to its UF and fills the city and the state missing from the CEP.
+func Normalize(a *model.Address) {
+	a.Street = title(a.Street, streetTypes, titles)
+	a.Number = strings.ToUpper(collapse(a.Number))
+	if n := strings.ReplaceAll(strings.ReplaceAll(a.Number, ".", ""), "/", ""); n == "SN" {
+		a.Number = "S/N"
+	}
+	a.Complement = title(a.Complement, nil, complements)
+	a.Neighborhood = title(a.Neighborhood, nil, titles)
+	a.City = title(a.City, nil, titles)
+	a.State = state(a.State)
+	a.CEP = FormatCEP(a.CEP)
+	a.Country = country(a.Country)
+
+	if location, ok := Lookup(a.CEP); ok {
+		if a.State == "" {
+			a.State = location.State
+		}
+		if a.City == "" && a.State == location.State {
+			a.City = location.City
+		}
+	}
+}
+
+// Check checks that the CEP belongs to the state of the address,
+// the CEPs out of the dataset are accepted.
+func Check(a model.Address) error {
+	location, ok := Lookup(a.CEP)
+	if !ok || a.State == "" || a.State == location.State {
+		return nil
+	}
+	return errors.New("CEP não pertence ao estado " + a.State + ".")
+}
+
+// title capitalizes each word of s but the particles, expanding the abbreviations of first
+// only at the start of s, and the ones of anywhere in every word. The acronyms, like "JK", are
+// kept as written, unless the whole of s is in upper case.
+func title(s string, first, anywhere map[string]string) string {
+	keepUpper := s != strings.ToUpper(s)
+	words := strings.Fields(s)
+	for i, word := range words {
+		key := strings.ToLower(strings.TrimSuffix(word, "."))
+		if expanded, ok := first[key]; ok && i == 0 {
+			words[i] = expanded
+			continue
+		}
+		if expanded, ok := anywhere[key]; ok {
+			words[i] = expanded
+			continue
+		}
+		if i > 0 && particles[key] {
+			words[i] = key
+			continue
+		}
+		if keepUpper && len(word) > 1 && word == strings.ToUpper(word) {
+			continue
+		}
+		words[i] = capitalize(word)
+	}
+	return strings.Join(words, " ")
+}
+
+// capitalize upper cases the first letter of the word and lower cases the others,
+// words with digits, like "12B", and Roman numerals, like "XV", are upper cased
+func capitalize(word string) string {
+	if strings.IndexFunc(word, unicode.IsDigit) >= 0 || romanNumeral.MatchString(strings.TrimSuffix(word, ".")) {
+		return strings.ToUpper(word)
+	}
+	word = strings.ToLower(word)
+	r, size := utf8.DecodeRuneInString(word)
+	return string(unicode.ToUpper(r)) + word[size:]
+}
+
+// state returns the UF of the state, given by the UF or the name
+func state(s string) string {
+	s = collapse(s)
+	if uf, ok := states[search.Normalize(s)]; ok {
+		return uf
+	}
+	return strings.ToUpper(s)
+}
+
+// country returns DefaultCountry for the empty country and its spellings
+func country(s string) string {
+	s = collapse(s)
+	if s == "" || countries[search.Normalize(s)] {
+		return DefaultCountry
+	}
+	return title(s, nil, nil)
+}
+
+// collapse trims s and replaces the runs of spaces by one
+func collapse(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
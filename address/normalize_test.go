@@ -0,0 +1,65 @@
+package address
+
+import (
+	"testing"
+
+	"github.com/lucasfloriani/go-mongo/model"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   model.Address
+		want model.Address
+	}{
+		{
+			"casing and abbreviations",
+			model.Address{Street: "  r. das  flores ", Number: "s/n", Complement: "ap 12 bl b", Neighborhood: "jd. américa", City: "são paulo", State: "sp", CEP: "01001000", Country: "br"},
+			model.Address{Street: "Rua das Flores", Number: "S/N", Complement: "Apto 12 Bloco B", Neighborhood: "Jardim América", City: "São Paulo", State: "SP", CEP: "01001-000", Country: "Brasil"},
+		},
+		{
+			"roman numerals",
+			model.Address{Street: "rua xv de novembro", Number: "100a", City: "Curitiba", State: "PR", Country: "Brasil"},
+			model.Address{Street: "Rua XV de Novembro", Number: "100A", City: "Curitiba", State: "PR", Country: "Brasil"},
+		},
+		{
+			"acronyms kept",
+			model.Address{Street: "av. JK", Number: "10", Neighborhood: "Vila Olímpia", City: "São Paulo", State: "SP", Country: "Brasil"},
+			model.Address{Street: "Avenida JK", Number: "10", Neighborhood: "Vila Olímpia", City: "São Paulo", State: "SP", Country: "Brasil"},
+		},
+		{
+			"upper case input",
+			model.Address{Street: "AV. DR. ARNALDO", Number: "455", City: "SAO PAULO", State: "SP", Country: "Brasil"},
+			model.Address{Street: "Avenida Doutor Arnaldo", Number: "455", City: "Sao Paulo", State: "SP", Country: "Brasil"},
+		},
+		{
+			"names like numerals",
+			model.Address{Street: "rua di cavalcanti", Number: "1", City: "Rio de Janeiro", State: "RJ", Country: "Brasil"},
+			model.Address{Street: "Rua Di Cavalcanti", Number: "1", City: "Rio de Janeiro", State: "RJ", Country: "Brasil"},
+		},
+		{
+			"state name and location from the CEP",
+			model.Address{Street: "Rua Direita", Number: "5", State: "minas gerais", CEP: "30130-000"},
+			model.Address{Street: "Rua Direita", Number: "5", City: "Belo Horizonte", State: "MG", CEP: "30130-000", Country: "Brasil"},
+		},
+		{
+			"city of the CEP not taken for another state",
+			model.Address{Street: "Rua Direita", Number: "5", State: "RJ", CEP: "30130-000"},
+			model.Address{Street: "Rua Direita", Number: "5", State: "RJ", CEP: "30130-000", Country: "Brasil"},
+		},
+		{
+			"foreign country",
+			model.Address{Street: "Calle Florida", Number: "100", City: "buenos aires", Country: "argentina"},
+			model.Address{Street: "Calle Florida", Number: "100", City: "Buenos Aires", Country: "Argentina"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.in
+			Normalize(&got)
+			if got != test.want {
+				t.Errorf("Normalize(%+v) = %+v, want %+v", test.in, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package address
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/lucasfloriani/go-mongo/model"
+)
+
+var (
+	cepPattern     = regexp.MustCompile(`(?i)(cep:?\s*)?\b(\d{5})-?(\d{3})\b`)
+	numberPattern  = regexp.MustCompile(`(?i)^(n[º°o]?\.?\s*)?(\d+[a-z]?|s\.?/?n\.?)$`)
+	trailingNumber = regexp.MustCompile(`^(\S+\s+.*\D)\s+(\d+[A-Za-z]?)$`)
+	cityState      = regexp.MustCompile(`^(.+?)\s*/\s*([A-Za-z]{2})$`)
+	separators     = regexp.MustCompile(`,|\s+-\s+|\s+–\s+`)
+)
+
+// Parse splits a free-text address, like "Rua das Flores, 100 - Apto 12 - Centro, São Paulo - SP, 01001-000",
+// in the fields of model.Address and normalizes them. The fields that can't be found are left empty,
+// but the city and the state may be filled from the CEP.
+func Parse(text string) model.Address {
+	var a model.Address
+	if m := cepPattern.FindStringSubmatchIndex(text); m != nil {
+		a.CEP = text[m[4]:m[5]] + text[m[6]:m[7]]
+		text = text[:m[0]] + text[m[1]:]
+	}
+
+	var parts []string
+	for _, part := range separators.Split(text, -1) {
+		if part = collapse(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		Normalize(&a)
+		return a
+	}
+
+	a.Street, parts = parts[0], parts[1:]
+	if m := trailingNumber.FindStringSubmatch(a.Street); m != nil {
+		a.Street, a.Number = m[1], m[2]
+	} else if len(parts) > 0 && numberPattern.MatchString(parts[0]) {
+		a.Number, parts = numberPattern.FindStringSubmatch(parts[0])[2], parts[1:]
+	}
+
+	// The country, then the state and the city are at the end
+	if n := len(parts); n > 0 && countries[strings.ToLower(parts[n-1])] {
+		a.Country, parts = parts[n-1], parts[:n-1]
+	}
+	if n := len(parts); n > 0 {
+		if m := cityState.FindStringSubmatch(parts[n-1]); m != nil && isState(m[2]) {
+			a.City, a.State, parts = m[1], m[2], parts[:n-1]
+		} else if isState(parts[n-1]) {
+			a.State, parts = parts[n-1], parts[:n-1]
+			if n > 1 {
+				a.City, parts = parts[n-2], parts[:n-2]
+			}
+		} else if n > 1 {
+			a.City, parts = parts[n-1], parts[:n-1]
+		}
+	}
+
+	// The neighborhood is the last of the remaining parts, unless it is a complement
+	if n := len(parts); n > 0 && !isComplement(parts[n-1]) {
+		a.Neighborhood, parts = parts[n-1], parts[:n-1]
+	}
+	a.Complement = strings.Join(parts, " - ")
+
+	Normalize(&a)
+	return a
+}
+
+// isState check if s is the UF of a state, the names aren't taken
+// since some are also the names of cities, like "Rio de Janeiro"
+func isState(s string) bool {
+	uf := strings.ToUpper(s)
+	for _, v := range states {
+		if v == uf {
+			return true
+		}
+	}
+	return false
+}
+
+// isComplement check if s starts with the abbreviation of a complement, like "Apto 12"
+func isComplement(s string) bool {
+	word := strings.Fields(s)[0]
+	_, ok := complements[strings.ToLower(strings.TrimSuffix(word, "."))]
+	return ok
+}
@@ -0,0 +1,46 @@
+package address
+
+import (
+	"testing"
+
+	"github.com/lucasfloriani/go-mongo/model"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text string
+		want model.Address
+	}{
+		{
+			"Rua das Flores, 100 - Apto 12 - Centro, São Paulo - SP, 01001-000",
+			model.Address{Street: "Rua das Flores", Number: "100", Complement: "Apto 12", Neighborhood: "Centro", City: "São Paulo", State: "SP", CEP: "01001-000", Country: "Brasil"},
+		},
+		{
+			"av. paulista 1578, bela vista, são paulo/sp",
+			model.Address{Street: "Avenida Paulista", Number: "1578", Neighborhood: "Bela Vista", City: "São Paulo", State: "SP", Country: "Brasil"},
+		},
+		{
+			"Rua XV de Novembro, s/n, Curitiba, PR, Brasil",
+			model.Address{Street: "Rua XV de Novembro", Number: "S/N", City: "Curitiba", State: "PR", Country: "Brasil"},
+		},
+		{
+			"Rua Direita, nº 5 - CEP 30130-000",
+			model.Address{Street: "Rua Direita", Number: "5", City: "Belo Horizonte", State: "MG", CEP: "30130-000", Country: "Brasil"},
+		},
+		{
+			"Rua Direita",
+			model.Address{Street: "Rua Direita", Country: "Brasil"},
+		},
+		{
+			"",
+			model.Address{Country: "Brasil"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			if got := Parse(test.text); got != test.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", test.text, got, test.want)
+			}
+		})
+	}
+}
@@ -2,61 +2,436 @@ package app
 
 import (
 	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/go-ozzo/ozzo-validation"
 	"github.com/spf13/viper"
 )
 
 // Config stores the application-wide configurations and
-// can be used in any place with app.Config.Variable
+// can be used in any place with app.Config.Variable.
+// It keeps the settings loaded at startup, the settings that can be reloaded
+// without restart must be read with Current.
 var Config appConfig
 
+// loaded reports if Config was loaded and validated
+var loaded bool
+
 type appConfig struct {
-	// Environment select the environment that will be used. Defaults to "production"
-	Environment string `mapstructure:"environment"`
+	// Environment select the environment, declared in "environments", whose settings
+	// override the shared ones. Defaults to "production"
+	Environment string `mapstructure:"environment" required:"true" reload:"restart"`
 	// ServerPort is the server port. Defaults to 8080
-	ServerPort int `mapstructure:"server_port"`
+	ServerPort int `mapstructure:"server_port" reload:"restart"`
+	// ShutdownTimeout is the grace period to drain requests and jobs on shutdown. Defaults to 30s
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout" reload:"restart"`
+	// ShutdownDelay is the time /readyz reports the shutdown before the server stops accepting
+	// connections, at least one readiness probe period so no new requests are routed to it. Defaults to 5s
+	ShutdownDelay time.Duration `mapstructure:"shutdown_delay" reload:"restart"`
+	// Log configures the application logs
+	Log struct {
+		// Format is "json" or "text". Defaults to "json" in production and "text" otherwise
+		Format string `mapstructure:"format"`
+		// Level is the default level: "debug", "info", "warn" or "error". Defaults to "info"
+		Level string `mapstructure:"level"`
+		// Levels overrides the default level by package (e.g. http: warn)
+		Levels map[string]string `mapstructure:"levels"`
+	} `mapstructure:"log"`
+	// Storage selects where the data is kept: "mongo" or "memory", which loses the data on
+	// restart and is meant for demos and tests. Defaults to "mongo"
+	Storage string `mapstructure:"storage" reload:"restart"`
 	// Database gets info to connect to db
-	Database struct {
-		Test struct {
-			Connection string `required:"true"`
-			Database   string `required:"true"`
-		}
-		Development struct {
-			Connection string `required:"true"`
-			Database   string `required:"true"`
+	Database databaseConfig `mapstructure:"database" reload:"restart"`
+	// Authorization declares the roles and the permissions granted to each one
+	Authorization authorizationConfig `mapstructure:"authorization"`
+	// RateLimit configures the limits of requests per client
+	RateLimit rateLimitConfig `mapstructure:"rate_limit"`
+	// Idempotency configures the responses stored for requests with an Idempotency-Key
+	Idempotency struct {
+		// TTL is how long the responses are replayed. Defaults to 24h
+		TTL time.Duration `mapstructure:"ttl"`
+		// MaxBodySize limits, in bytes, the bodies of the requests with a key. Defaults to 1MB
+		MaxBodySize int64 `mapstructure:"max_body_size"`
+	} `mapstructure:"idempotency" reload:"restart"`
+	// Health configures the health checks
+	Health struct {
+		// Timeout is the max duration of each dependency check. Defaults to 2s
+		Timeout time.Duration `mapstructure:"timeout"`
+	} `mapstructure:"health"`
+	// Pagination configures the page sizes of the list endpoints
+	Pagination struct {
+		// DefaultPageSize is used when per_page isn't sent. Defaults to helper.DefaultPageSize
+		DefaultPageSize int `mapstructure:"default_page_size"`
+		// MaxPageSize limits per_page. Defaults to helper.MaxPageSize
+		MaxPageSize int `mapstructure:"max_page_size"`
+	} `mapstructure:"pagination"`
+	// Reports configures the aggregated reports of the users
+	Reports struct {
+		// AgeBuckets are the default boundaries of the age histogram. Defaults to 18, 25, 35, 45, 55, 65 and 120
+		AgeBuckets []uint `mapstructure:"age_buckets"`
+	} `mapstructure:"reports"`
+	// Metrics configures the Prometheus metrics
+	Metrics struct {
+		// Path is where the metrics are exposed. Defaults to "/metrics"
+		Path string `mapstructure:"path"`
+		// Namespace prefixes the metric names. Defaults to "gomongo"
+		Namespace string `mapstructure:"namespace"`
+		// HTTPBuckets are the buckets in seconds of the HTTP request durations
+		HTTPBuckets []float64 `mapstructure:"http_buckets"`
+		// DAOBuckets are the buckets in seconds of the DAO operation durations
+		DAOBuckets []float64 `mapstructure:"dao_buckets"`
+	} `mapstructure:"metrics" reload:"restart"`
+	// Tracing configures the OpenTelemetry traces
+	Tracing struct {
+		// Enabled turns the traces export on. Defaults to false
+		Enabled bool `mapstructure:"enabled"`
+		// Exporter selects where the traces are sent: "otlp", "stdout" or "file". Defaults to "otlp"
+		Exporter string `mapstructure:"exporter"`
+		// Endpoint is the host:port of the OTLP gRPC collector. Defaults to "localhost:4317"
+		Endpoint string `mapstructure:"endpoint"`
+		// Insecure disables TLS with the OTLP collector
+		Insecure bool `mapstructure:"insecure"`
+		// File is the path of the file used by the "file" exporter
+		File string `mapstructure:"file"`
+		// ServiceName is the name of the service in the traces. Defaults to "go-mongo"
+		ServiceName string `mapstructure:"service_name"`
+		// SampleRatio is the ratio of the traces started by this service that are sampled. Defaults to 1
+		SampleRatio float64 `mapstructure:"sample_ratio"`
+	} `mapstructure:"tracing" reload:"restart"`
+}
+
+type databaseConfig struct {
+	// Connection is the connection string, options set below take precedence over its options
+	Connection string `mapstructure:"connection" required:"true" secret:"url"`
+	// Database is the name of the database used by the application
+	Database string `mapstructure:"database" required:"true"`
+	// AppName identifies the application in the server logs
+	AppName string `mapstructure:"app_name"`
+	// TLS configures the encryption of the connections
+	TLS struct {
+		Enabled bool `mapstructure:"enabled"`
+		// CAFile is the PEM file with the certificate authorities trusted
+		CAFile string `mapstructure:"ca_file"`
+		// CertKeyFile is the PEM file with the client certificate and its key
+		CertKeyFile string `mapstructure:"cert_key_file"`
+		// Insecure skips the verification of the server certificate
+		Insecure bool `mapstructure:"insecure"`
+	} `mapstructure:"tls"`
+	// Auth configures the credentials, the password should be set with GOMONGO_DATABASE_AUTH_PASSWORD(_FILE)
+	Auth struct {
+		// Mechanism is the auth mechanism, e.g. SCRAM-SHA-256, MONGODB-X509
+		Mechanism string `mapstructure:"mechanism"`
+		// Source is the database where the credentials are stored. Defaults to "admin"
+		Source   string `mapstructure:"source"`
+		Username string `mapstructure:"username"`
+		Password string `mapstructure:"password" secret:"true"`
+	} `mapstructure:"auth"`
+	// MaxPoolSize limits the connections per server
+	MaxPoolSize uint16 `mapstructure:"max_pool_size"`
+	// ConnectTimeout limits the time to open a connection and to ping the database, it must be positive
+	ConnectTimeout time.Duration `mapstructure:"connect_timeout"`
+	// ServerSelectionTimeout limits the time to find a server for an operation
+	ServerSelectionTimeout time.Duration `mapstructure:"server_selection_timeout"`
+	// SocketTimeout limits the time to read or write in a connection
+	SocketTimeout time.Duration `mapstructure:"socket_timeout"`
+	// Retry configures the retries of the connection at startup and of the reads
+	Retry struct {
+		// Deadline limits the time spent connecting at startup. Defaults to 1m
+		Deadline time.Duration `mapstructure:"deadline"`
+		// InitialBackoff is the wait after the first failed attempt, doubled on each attempt. Defaults to 500ms
+		InitialBackoff time.Duration `mapstructure:"initial_backoff"`
+		// MaxBackoff limits the wait between attempts. Defaults to 10s
+		MaxBackoff time.Duration `mapstructure:"max_backoff"`
+		// ReadAttempts is the number of attempts of the idempotent reads on transient errors. Defaults to 3
+		ReadAttempts int `mapstructure:"read_attempts"`
+	} `mapstructure:"retry"`
+	// Indexes configures the synchronization of the indexes declared by the DAOs
+	Indexes struct {
+		// Sync creates the missing indexes at startup. Defaults to true
+		Sync bool `mapstructure:"sync"`
+		// DropUnexpected drops the indexes that aren't declared at startup. Defaults to false
+		DropUnexpected bool `mapstructure:"drop_unexpected"`
+	} `mapstructure:"indexes"`
+	// Migrations configures the schema migrations
+	Migrations struct {
+		// Auto applies the pending migrations at startup. Defaults to false
+		Auto bool `mapstructure:"auto"`
+		// Directory is where "migrate create" writes the new migrations. Defaults to migration
+		Directory string `mapstructure:"directory"`
+	} `mapstructure:"migrations"`
+	// Concerns are the read preference, read concern and write concern of every collection
+	Concerns ConcernsConfig `mapstructure:",squash"`
+	// Collections overrides the concerns by collection, e.g. majority writes for "user"
+	Collections map[string]ConcernsConfig `mapstructure:"collections"`
+}
+
+// ConcernsConfig configures how the operations are read from and written to the replica set.
+// Empty values keep the ones of the connection string or of the client.
+type ConcernsConfig struct {
+	// ReadPreference is the mode, e.g. primary, secondaryPreferred
+	ReadPreference string `mapstructure:"read_preference"`
+	// ReadConcern is the level, e.g. local, majority
+	ReadConcern string `mapstructure:"read_concern"`
+	// WriteConcern is the number of acknowledgements or "majority"
+	WriteConcern string `mapstructure:"write_concern"`
+	// WriteJournal waits for the writes to be committed to the journal
+	WriteJournal bool `mapstructure:"write_journal"`
+	// WriteTimeout limits the time waiting for the write concern
+	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+}
+
+var (
+	readPreferences = []interface{}{"primary", "primaryPreferred", "secondary", "secondaryPreferred", "nearest"}
+	readConcerns    = []interface{}{"local", "available", "majority", "linearizable", "snapshot"}
+	writeConcern    = regexp.MustCompile(`^(majority|[0-9]+)$`)
+)
+
+// Validate check if the concerns are supported by the driver
+func (config ConcernsConfig) Validate() error {
+	return validation.ValidateStruct(&config,
+		validation.Field(&config.ReadPreference, validation.In(readPreferences...)),
+		validation.Field(&config.ReadConcern, validation.In(readConcerns...)),
+		validation.Field(&config.WriteConcern, validation.Match(writeConcern)),
+	)
+}
+
+// Validate check if the client options are supported by the driver and the TLS files exist.
+func (config databaseConfig) Validate() error {
+	if config.ConnectTimeout <= 0 {
+		return fmt.Errorf("database.connect_timeout must be greater than 0")
+	}
+	if config.TLS.Enabled {
+		for _, file := range []string{config.TLS.CAFile, config.TLS.CertKeyFile} {
+			if _, err := os.Stat(file); file != "" && err != nil {
+				return fmt.Errorf("database.tls: %s", err)
+			}
 		}
-		Production struct {
-			Connection string `required:"true"`
-			Database   string `required:"true"`
+	}
+	if err := config.Concerns.Validate(); err != nil {
+		return fmt.Errorf("database: %s", err)
+	}
+	for name, concerns := range config.Collections {
+		if err := concerns.Validate(); err != nil {
+			return fmt.Errorf("database.collections.%s: %s", name, err)
 		}
 	}
+	return nil
+}
+
+type authorizationConfig struct {
+	// AnonymousRole is the role used by callers that weren't identified
+	AnonymousRole string `mapstructure:"anonymous_role"`
+	// Roles maps each role name to its permissions, "*" grants every permission
+	Roles map[string][]string `mapstructure:"roles"`
+}
+
+type rateLimitConfig struct {
+	// Enabled turns the rate limiting on. Defaults to false
+	Enabled bool `mapstructure:"enabled"`
+	// Default is the limit used by routes that don't match any rule
+	Default RateLimitRule `mapstructure:"default"`
+	// Rules overrides the default limit by route group and HTTP method
+	Rules []RateLimitRule `mapstructure:"rules"`
+}
+
+// RateLimitRule limits the requests of each client to Requests per Period with bursts up to Burst.
+// Group and Method select the routes that use the rule, empty values match every route.
+type RateLimitRule struct {
+	Group    string        `mapstructure:"group"`
+	Method   string        `mapstructure:"method"`
+	Requests int           `mapstructure:"requests"`
+	Period   time.Duration `mapstructure:"period"`
+	Burst    int           `mapstructure:"burst"`
+}
+
+// Validate check if the rule limits are positive
+func (rule RateLimitRule) Validate() error {
+	return validation.ValidateStruct(&rule,
+		validation.Field(&rule.Requests, validation.Required, validation.Min(1)),
+		validation.Field(&rule.Period, validation.Required, validation.Min(time.Millisecond)),
+		validation.Field(&rule.Burst, validation.Min(0)),
+	)
+}
+
+// Validate check if the default limit and the rules are valid when the rate limiting is enabled.
+func (config rateLimitConfig) Validate() error {
+	if !config.Enabled {
+		return nil
+	}
+	return validation.ValidateStruct(&config,
+		validation.Field(&config.Default),
+		validation.Field(&config.Rules),
+	)
 }
 
 // Validate check if the required config about the aplication is filled.
 // Emmits a panic error if doesn't
 func (config appConfig) Validate() error {
+	if err := validateRequired(reflect.ValueOf(config), ""); err != nil {
+		return err
+	}
 	return validation.ValidateStruct(&config,
-		validation.Field(&config.Database, validation.Required),
+		validation.Field(&config.Storage, validation.In("mongo", "memory")),
+		validation.Field(&config.Database),
+		validation.Field(&config.Authorization),
+		validation.Field(&config.RateLimit),
 	)
 }
 
+// Validate check if the anonymous role is one of the declared roles.
+func (config authorizationConfig) Validate() error {
+	if config.AnonymousRole == "" {
+		return nil
+	}
+	if _, ok := config.Roles[config.AnonymousRole]; !ok {
+		return fmt.Errorf("anonymous role %q is not declared in roles", config.AnonymousRole)
+	}
+	return nil
+}
+
+// validateRequired check if every field tagged with required:"true" is filled,
+// the path of the field in the configuration file is used in the error
+func validateRequired(value reflect.Value, path string) error {
+	for i := 0; i < value.NumField(); i++ {
+		field, fieldValue := value.Type().Field(i), value.Field(i)
+		name := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if path != "" {
+			name = path + "." + name
+		}
+		if strings.HasSuffix(field.Tag.Get("mapstructure"), ",squash") {
+			name = path
+		}
+		if field.Tag.Get("required") == "true" && isZero(fieldValue) {
+			return fmt.Errorf("%s is required", name)
+		}
+		if fieldValue.Kind() == reflect.Struct && fieldValue.Type() != reflect.TypeOf(time.Time{}) {
+			if err := validateRequired(fieldValue, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isZero check if the value is the zero value of its type, empty maps and slices included
+func isZero(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Map, reflect.Slice:
+		return value.Len() == 0
+	default:
+		return reflect.DeepEqual(value.Interface(), reflect.Zero(value.Type()).Interface())
+	}
+}
+
 // LoadConfig loads configuration from the given list of paths and populates it into the Config variable.
+// Each setting is taken from the first source that sets it, in this order:
+//  1. command-line flags (see Flags)
+//  2. GOMONGO_ prefixed environment variables, e.g. GOMONGO_DATABASE_CONNECTION
+//  3. files named by the *_FILE environment variables, e.g. GOMONGO_DATABASE_CONNECTION_FILE
+//  4. the selected environment in "environments" of app.yaml
+//  5. the shared settings of app.yaml
+//  6. the defaults
 func LoadConfig(configPaths ...string) error {
+	config, file, err := load(configPaths)
+	if err != nil {
+		return err
+	}
+	Config = *config
+	current.Store(config)
+	paths, configFile = configPaths, file
+	loaded = true
+	return nil
+}
+
+// load reads and validates the configuration, returning it with the path of the file read
+func load(configPaths []string) (*appConfig, string, error) {
 	v := viper.New()
 	v.SetConfigType("yaml")
 	v.SetConfigName("app")
 	v.SetDefault("environment", "production")
 	v.SetDefault("server_port", 8080)
-	v.AutomaticEnv()
+	v.SetDefault("shutdown_timeout", 30*time.Second)
+	v.SetDefault("shutdown_delay", 5*time.Second)
+	v.SetDefault("log.level", "info")
+	v.SetDefault("storage", "mongo")
+	v.SetDefault("database.app_name", "go-mongo")
+	v.SetDefault("database.auth.source", "admin")
+	v.SetDefault("database.connect_timeout", 10*time.Second)
+	v.SetDefault("database.server_selection_timeout", 30*time.Second)
+	v.SetDefault("database.indexes.sync", true)
+	v.SetDefault("database.migrations.directory", "migration")
+	v.SetDefault("database.retry.deadline", time.Minute)
+	v.SetDefault("database.retry.initial_backoff", 500*time.Millisecond)
+	v.SetDefault("database.retry.max_backoff", 10*time.Second)
+	v.SetDefault("database.retry.read_attempts", 3)
+	v.SetDefault("idempotency.ttl", 24*time.Hour)
+	v.SetDefault("idempotency.max_body_size", 1<<20)
+	v.SetDefault("health.timeout", 2*time.Second)
+	v.SetDefault("reports.age_buckets", []uint{18, 25, 35, 45, 55, 65, 120})
+	v.SetDefault("tracing.exporter", "otlp")
+	v.SetDefault("tracing.endpoint", "localhost:4317")
+	v.SetDefault("tracing.service_name", "go-mongo")
+	v.SetDefault("tracing.sample_ratio", 1.0)
+	v.SetDefault("metrics.path", "/metrics")
+	v.SetDefault("metrics.namespace", "gomongo")
+	v.SetDefault("metrics.http_buckets", []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5})
+	v.SetDefault("metrics.dao_buckets", []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1})
+	if err := bindOverrides(v); err != nil {
+		return nil, "", err
+	}
 	for _, path := range configPaths {
 		v.AddConfigPath(path)
 	}
 	if err := v.ReadInConfig(); err != nil {
-		return fmt.Errorf("Failed to read the configuration file: %s", err)
+		return nil, "", fmt.Errorf("Failed to read the configuration file: %s", err)
 	}
-	if err := v.Unmarshal(&Config); err != nil {
-		return err
+	if err := mergeEnvironment(v); err != nil {
+		return nil, "", err
+	}
+	config := &appConfig{}
+	if err := v.Unmarshal(config); err != nil {
+		return nil, "", err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, "", err
 	}
-	return Config.Validate()
+	return config, v.ConfigFileUsed(), nil
+}
+
+// mergeEnvironment merges the overrides of the selected environment over the shared settings.
+// The selected environment must be declared in "environments", even without overrides.
+func mergeEnvironment(v *viper.Viper) error {
+	environment := strings.ToLower(v.GetString("environment"))
+	environments := v.GetStringMap("environments")
+	overrides, ok := environments[environment]
+	if !ok {
+		names := make([]string, 0, len(environments))
+		for name := range environments {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("Unknown environment %q, the declared environments are: %s", environment, strings.Join(names, ", "))
+	}
+	v.Set("environment", environment)
+	if overrides == nil {
+		return nil
+	}
+	settings, ok := overrides.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("Invalid settings of the environment %q", environment)
+	}
+	return v.MergeConfigMap(settings)
+}
+
+// Loaded reports if the configuration was loaded and validated by LoadConfig.
+func Loaded() bool {
+	return loaded
 }
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"regexp"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+// scopeKey is the key used to store the RequestScope in the echo context
+const scopeKey = "scope"
+
+// requestIDPattern limits the request IDs accepted from clients
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// Init returns a middleware that prepares the request scope used by the next handlers,
+// propagating the X-Request-ID of the request (or generating one) and writing the access log.
+func Init() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			requestID := c.Request().Header.Get(echo.HeaderXRequestID)
+			if !requestIDPattern.MatchString(requestID) {
+				requestID = newRequestID()
+			}
+			c.Response().Header().Set(echo.HeaderXRequestID, requestID)
+
+			rs := NewRequestScope(c.Request().Context(), requestID)
+			c.Set(scopeKey, rs)
+
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+
+			status := c.Response().Status
+			level := slog.LevelInfo
+			switch {
+			case status >= 500:
+				level = slog.LevelError
+			case status >= 400:
+				level = slog.LevelWarn
+			}
+			rs.Logger().Log(c.Request().Context(), level, "request",
+				slog.String("method", c.Request().Method),
+				slog.String("route", c.Path()),
+				slog.String("path", c.Request().URL.Path),
+				slog.Int("status", status),
+				slog.Int64("bytes", c.Response().Size),
+				slog.Duration("latency", time.Since(start)),
+				slog.String("ip", c.RealIP()),
+				slog.String("caller", rs.Identity().ID),
+			)
+			return nil
+		}
+	}
+}
+
+// GetRequestScope returns the RequestScope of the current request.
+func GetRequestScope(c echo.Context) RequestScope {
+	return c.Get(scopeKey).(RequestScope)
+}
+
+// newRequestID generates a random request ID
+func newRequestID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,152 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// redacted replaces the values of the attributes that hold personal data
+const redacted = "[REDACTED]"
+
+var (
+	// redactedKeys are the attribute keys whose values are never logged
+	redactedKeys = map[string]bool{"name": true, "phone": true, "phones": true, "number": true}
+	// phonePattern matches brazilian phone numbers written in any format,
+	// redactPhones discards the matches that are part of longer words or numbers
+	phonePattern = regexp.MustCompile(`(\+?55\s?)?\(?\d{2}\)?\s?9?\d{4}[\s-]?\d{4}`)
+
+	loggerMu sync.Mutex
+	handler  slog.Handler = slog.NewTextHandler(os.Stderr, nil)
+	levels                = map[string]*slog.LevelVar{}
+)
+
+// InitLogger sets up the handler of the loggers with the format and levels of Current().Log,
+// it can be called again to apply a reloaded configuration.
+// The format defaults to JSON in production and to text in the other environments.
+func InitLogger() {
+	loggerMu.Lock()
+	defer loggerMu.Unlock()
+
+	opts := &slog.HandlerOptions{Level: slog.LevelDebug, ReplaceAttr: redact}
+	config := Current()
+	format := config.Log.Format
+	if format == "" && config.Environment == "production" {
+		format = "json"
+	}
+	if format == "json" {
+		handler = slog.NewJSONHandler(os.Stdout, opts)
+	} else {
+		handler = slog.NewTextHandler(os.Stdout, opts)
+	}
+	for pkg, level := range levels {
+		level.Set(levelOf(pkg))
+	}
+}
+
+// Logger returns the logger of the given package, filtered by the level configured for it.
+func Logger(pkg string) *slog.Logger {
+	loggerMu.Lock()
+	defer loggerMu.Unlock()
+
+	level, ok := levels[pkg]
+	if !ok {
+		level = new(slog.LevelVar)
+		level.Set(levelOf(pkg))
+		levels[pkg] = level
+	}
+	return slog.New(&levelHandler{level: level}).With(slog.String("package", pkg))
+}
+
+// levelOf returns the level configured for the package, falling back to the default level
+func levelOf(pkg string) slog.Level {
+	config := Current()
+	value, ok := config.Log.Levels[pkg]
+	if !ok {
+		value = config.Log.Level
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// redact hides the values of personal data attributes and the phone numbers inside the messages.
+// The other attributes are kept as written, since IDs and tokens can look like phone numbers.
+func redact(groups []string, attr slog.Attr) slog.Attr {
+	if redactedKeys[strings.ToLower(attr.Key)] {
+		return slog.String(attr.Key, redacted)
+	}
+	if len(groups) == 0 && attr.Key == slog.MessageKey && attr.Value.Kind() == slog.KindString {
+		return slog.String(attr.Key, redactPhones(attr.Value.String()))
+	}
+	return attr
+}
+
+// redactPhones replaces the phone numbers in the text that aren't part of a longer word or number
+func redactPhones(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, match := range phonePattern.FindAllStringIndex(text, -1) {
+		start, end := match[0], match[1]
+		if start > 0 && isWordByte(text[start-1]) || end < len(text) && isWordByte(text[end]) {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(redacted)
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// isWordByte check if the byte is an ASCII letter or digit
+func isWordByte(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// levelHandler filters the records by the level of a package before sending them
+// to the current handler, so InitLogger affects the loggers created before it
+type levelHandler struct {
+	level slog.Leveler
+	// wrap applies the attributes and groups added to the logger over the current handler
+	wrap func(slog.Handler) slog.Handler
+}
+
+func (h *levelHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *levelHandler) Handle(ctx context.Context, record slog.Record) error {
+	loggerMu.Lock()
+	next := handler
+	loggerMu.Unlock()
+
+	if h.wrap != nil {
+		next = h.wrap(next)
+	}
+	return next.Handle(ctx, record)
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithAttrs(attrs) })
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithGroup(name) })
+}
+
+// with returns a copy of the handler that also applies fn over the current handler
+func (h *levelHandler) with(fn func(slog.Handler) slog.Handler) slog.Handler {
+	wrap := h.wrap
+	return &levelHandler{h.level, func(next slog.Handler) slog.Handler {
+		if wrap != nil {
+			next = wrap(next)
+		}
+		return fn(next)
+	}}
+}
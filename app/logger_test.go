@@ -0,0 +1,49 @@
+package app
+
+import (
+	"log/slog"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name   string
+		groups []string
+		attr   slog.Attr
+		want   string
+	}{
+		{"personal key", nil, slog.String("name", "Maria Silva"), redacted},
+		{"personal key in group", []string{"user"}, slog.String("Phone", "(11) 91234-5678"), redacted},
+		{"phone in message", nil, slog.String(slog.MessageKey, "sms sent to (11) 91234-5678"), "sms sent to " + redacted},
+		{"international phone in message", nil, slog.String(slog.MessageKey, "call +55 11 91234-5678 now"), "call " + redacted + " now"},
+		{"digits in message id", nil, slog.String(slog.MessageKey, "user 5f1a2b3c4d11987654321aaa created"), "user 5f1a2b3c4d11987654321aaa created"},
+		{"request id", nil, slog.String("request_id", "11987654321"), "11987654321"},
+		{"object id", nil, slog.String("user_id", "5f1a2b3c11987654321aaaa0"), "5f1a2b3c11987654321aaaa0"},
+		{"message in group", []string{"job"}, slog.String(slog.MessageKey, "11987654321"), "11987654321"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := redact(test.groups, test.attr).Value.String(); got != test.want {
+				t.Errorf("redact(%v) = %q, want %q", test.attr, got, test.want)
+			}
+		})
+	}
+}
+
+func TestRedactPhones(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"", ""},
+		{"no phones here", "no phones here"},
+		{"11 91234-5678", redacted},
+		{"(11) 91234-5678 e (21) 3333-4444", redacted + " e " + redacted},
+		{"token 119123456781234", "token 119123456781234"},
+		{"order A11912345678", "order A11912345678"},
+	}
+	for _, test := range tests {
+		if got := redactPhones(test.text); got != test.want {
+			t.Errorf("redactPhones(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
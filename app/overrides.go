@@ -0,0 +1,104 @@
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix prefixes the environment variables that override the configuration,
+// e.g. GOMONGO_DATABASE_CONNECTION overrides database.connection
+const EnvPrefix = "GOMONGO"
+
+// Flags are the command-line flags that override the most common settings.
+// They must be parsed before LoadConfig is called.
+var Flags = pflag.NewFlagSet("go-mongo", pflag.ExitOnError)
+
+// flagKeys maps each flag to the configuration key it overrides
+var flagKeys = map[string]string{
+	"environment":         "environment",
+	"port":                "server_port",
+	"log-level":           "log.level",
+	"log-format":          "log.format",
+	"database-connection": "database.connection",
+	"database-name":       "database.database",
+}
+
+func init() {
+	Flags.StringP("config", "c", "./config", "directory of the app.yaml configuration file")
+	Flags.StringP("environment", "e", "", "environment whose settings are used")
+	Flags.IntP("port", "p", 0, "server port")
+	Flags.String("log-level", "", "default log level (debug, info, warn or error)")
+	Flags.String("log-format", "", "log format (json or text)")
+	Flags.String("database-connection", "", "Mongo connection string")
+	Flags.String("database-name", "", "Mongo database name")
+}
+
+// bindOverrides binds the flags and environment variables to every configuration key and applies
+// the secrets read from the files named by the *_FILE variables (e.g. GOMONGO_DATABASE_CONNECTION_FILE).
+// A secret file is ignored when the same key is set by a flag or by its environment variable.
+func bindOverrides(v *viper.Viper) error {
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	overriddenByFlag := map[string]bool{}
+	for name, key := range flagKeys {
+		flag := Flags.Lookup(name)
+		if !flag.Changed {
+			continue
+		}
+		if err := v.BindPFlag(key, flag); err != nil {
+			return err
+		}
+		overriddenByFlag[key] = true
+	}
+
+	for _, key := range configKeys(reflect.TypeOf(appConfig{}), "") {
+		env := EnvPrefix + "_" + strings.ToUpper(strings.Replace(key, ".", "_", -1))
+		if err := v.BindEnv(key, env); err != nil {
+			return err
+		}
+
+		file := os.Getenv(env + "_FILE")
+		if file == "" || overriddenByFlag[key] || os.Getenv(env) != "" {
+			continue
+		}
+		secret, err := ioutil.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("Failed to read %s_FILE: %s", env, err)
+		}
+		v.Set(key, strings.TrimRight(string(secret), "\r\n"))
+	}
+	return nil
+}
+
+// configKeys returns the keys of every leaf setting of the configuration type
+func configKeys(t reflect.Type, path string) (keys []string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if strings.HasSuffix(field.Tag.Get("mapstructure"), ",squash") {
+			keys = append(keys, configKeys(field.Type, path)...)
+			continue
+		}
+		name := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if path != "" {
+			name = path + "." + name
+		}
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Time{}) {
+			keys = append(keys, configKeys(field.Type, name)...)
+			continue
+		}
+		keys = append(keys, name)
+	}
+	return
+}
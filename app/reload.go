@@ -0,0 +1,155 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDelay groups the burst of events written by editors when saving the file
+const reloadDelay = 200 * time.Millisecond
+
+var (
+	// current holds the *appConfig swapped by Reload
+	current atomic.Value
+	// paths and configFile are where LoadConfig found the configuration
+	paths      []string
+	configFile string
+
+	reloadMu    sync.Mutex
+	subscribers = map[string][]func(){}
+)
+
+// Current returns the current configuration, including the changes applied by Reload.
+// The returned configuration must not be modified.
+func Current() *appConfig {
+	if config, ok := current.Load().(*appConfig); ok {
+		return config
+	}
+	return &Config
+}
+
+// Subscribe registers a function called after a reload changes the given top-level setting (e.g. "log").
+func Subscribe(key string, fn func()) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	subscribers[key] = append(subscribers[key], fn)
+}
+
+// Reload loads and validates the configuration again and swaps it with the current one.
+// Settings tagged with reload:"restart" keep their current values, the ones that changed are
+// reported. An invalid configuration is refused and the current one is kept.
+func Reload() error {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	logger := Logger("app")
+	next, _, err := load(paths)
+	if err != nil {
+		logger.Error("configuration reload refused", "error", err)
+		return err
+	}
+
+	prev := Current()
+	changed, restart := diffConfig(prev, next)
+	current.Store(next)
+
+	if len(restart) > 0 {
+		logger.Warn("configuration changes require restart", "settings", strings.Join(restart, ", "))
+	}
+	logger.Info("configuration reloaded", "changed", strings.Join(changed, ", "))
+	for _, key := range changed {
+		for _, fn := range subscribers[key] {
+			fn()
+		}
+	}
+	return nil
+}
+
+// diffConfig returns the top-level settings changed in next, restoring in next the
+// ones that can't be applied without restart
+func diffConfig(prev, next *appConfig) (changed, restart []string) {
+	prevValue, nextValue := reflect.ValueOf(prev).Elem(), reflect.ValueOf(next).Elem()
+	for i := 0; i < nextValue.NumField(); i++ {
+		field := nextValue.Type().Field(i)
+		if reflect.DeepEqual(prevValue.Field(i).Interface(), nextValue.Field(i).Interface()) {
+			continue
+		}
+		key := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+		if field.Tag.Get("reload") == "restart" {
+			nextValue.Field(i).Set(prevValue.Field(i))
+			restart = append(restart, key)
+			continue
+		}
+		changed = append(changed, key)
+	}
+	return
+}
+
+// WatchConfig reloads the configuration when its file changes or a SIGHUP is received,
+// until the application shuts down.
+func WatchConfig() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	// The directory is watched since editors and Kubernetes replace the file instead of writing it
+	if err := watcher.Add(filepath.Dir(configFile)); err != nil {
+		watcher.Close()
+		return err
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	target, _ := filepath.EvalSymlinks(configFile)
+
+	done := make(chan struct{})
+	Go("config watcher", func() error {
+		var timer *time.Timer
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return errors.New("watcher closed")
+				}
+				// The file itself is written or replaced by editors, while Kubernetes swaps the ..data
+				// symlink of the ConfigMap directory, which changes the file the config symlink resolves to
+				written := filepath.Base(event.Name) == filepath.Base(configFile) && event.Op&(fsnotify.Write|fsnotify.Create) != 0
+				resolved, _ := filepath.EvalSymlinks(configFile)
+				if !written && resolved == target {
+					continue
+				}
+				target = resolved
+				if timer != nil {
+					timer.Stop()
+				}
+				timer = time.AfterFunc(reloadDelay, func() { Reload() })
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return errors.New("watcher closed")
+				}
+				Logger("app").Error("configuration watch failed", "error", err)
+			case <-hup:
+				Reload()
+			case <-done:
+				return nil
+			}
+		}
+	})
+
+	OnShutdown("config watcher", func(context.Context) error {
+		signal.Stop(hup)
+		close(done)
+		return watcher.Close()
+	})
+	return nil
+}
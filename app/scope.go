@@ -0,0 +1,70 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Identity represents the caller of a request as identified by the authentication middlewares.
+type Identity struct {
+	// ID is the identifier of the caller (e.g. the user ID). Empty for anonymous callers
+	ID string
+	// Role is the role used to resolve the caller permissions
+	Role string
+	// Scopes restricts the caller permissions to the listed ones, used by API keys instead of Role
+	Scopes []string
+	// KeyID is the ID of the API key that identified the caller, empty for other callers
+	KeyID string
+}
+
+// RequestScope contains the application-specific information that is carried around in a request.
+type RequestScope interface {
+	// Context returns the context of the request
+	Context() context.Context
+	// RequestID returns the ID of the request, sent back in the X-Request-ID header
+	RequestID() string
+	// Logger returns the logger of the request, which adds the request ID to every line
+	Logger() *slog.Logger
+	// Identity returns the caller of the request
+	Identity() Identity
+	// SetIdentity sets the caller of the request
+	SetIdentity(identity Identity)
+}
+
+type requestScope struct {
+	ctx       context.Context
+	requestID string
+	logger    *slog.Logger
+	identity  Identity
+}
+
+// NewRequestScope creates a new RequestScope with the given context and request ID.
+// The caller starts as anonymous, using the configured anonymous role.
+func NewRequestScope(ctx context.Context, requestID string) RequestScope {
+	return &requestScope{
+		ctx:       ctx,
+		requestID: requestID,
+		logger:    Logger("http").With(slog.String("request_id", requestID)),
+		identity:  Identity{Role: Current().Authorization.AnonymousRole},
+	}
+}
+
+func (rs *requestScope) Context() context.Context {
+	return rs.ctx
+}
+
+func (rs *requestScope) RequestID() string {
+	return rs.requestID
+}
+
+func (rs *requestScope) Logger() *slog.Logger {
+	return rs.logger
+}
+
+func (rs *requestScope) Identity() Identity {
+	return rs.identity
+}
+
+func (rs *requestScope) SetIdentity(identity Identity) {
+	rs.identity = identity
+}
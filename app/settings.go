@@ -0,0 +1,73 @@
+package app
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// mask replaces the secrets in the printed settings
+const mask = "******"
+
+// passwordRegex matches the credentials of a connection string, which may have several hosts
+var passwordRegex = regexp.MustCompile(`^([a-z+]+://[^:/@]*:)[^@/]*@`)
+
+// Settings returns the current configuration keyed like the configuration file, to be printed.
+// The fields tagged with secret:"true" are masked, and so are the passwords of the
+// connection strings tagged with secret:"url".
+func Settings() map[string]interface{} {
+	return settings(reflect.ValueOf(*Current()))
+}
+
+// settings converts the configuration struct to a map keyed by the mapstructure names
+func settings(value reflect.Value) map[string]interface{} {
+	values := map[string]interface{}{}
+	for i := 0; i < value.NumField(); i++ {
+		field, fieldValue := value.Type().Field(i), value.Field(i)
+		tag := field.Tag.Get("mapstructure")
+		if strings.HasSuffix(tag, ",squash") {
+			for name, v := range settings(fieldValue) {
+				values[name] = v
+			}
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		values[name] = setting(fieldValue, field.Tag.Get("secret"))
+	}
+	return values
+}
+
+// setting converts a configuration value, masking it according to its secret tag
+func setting(value reflect.Value, secret string) interface{} {
+	switch {
+	case secret == "true" && !isZero(value):
+		return mask
+	case secret == "url":
+		return maskURL(value.String())
+	}
+
+	switch v := value.Interface().(type) {
+	case time.Duration:
+		return v.String()
+	}
+	switch value.Kind() {
+	case reflect.Struct:
+		return settings(value)
+	case reflect.Map:
+		values := map[string]interface{}{}
+		for _, key := range value.MapKeys() {
+			values[key.String()] = setting(value.MapIndex(key), "")
+		}
+		return values
+	}
+	return value.Interface()
+}
+
+// maskURL masks the password of a connection string, keeping the hosts and options readable
+func maskURL(connection string) string {
+	return passwordRegex.ReplaceAllString(connection, "${1}"+mask+"@")
+}
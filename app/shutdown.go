@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type shutdownHook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+var (
+	shutdownMu    sync.Mutex
+	shutdownHooks []shutdownHook
+	shuttingDown  bool
+)
+
+// OnShutdown registers a function called by Shutdown, used by background jobs to drain
+// and by buffered components to flush their data before the database is disconnected.
+func OnShutdown(name string, fn func(ctx context.Context) error) {
+	shutdownMu.Lock()
+	defer shutdownMu.Unlock()
+	shutdownHooks = append(shutdownHooks, shutdownHook{name, fn})
+}
+
+// StartShutdown marks the application as shutting down, reported by ShuttingDown,
+// before the server is drained and Shutdown is called.
+func StartShutdown() {
+	shutdownMu.Lock()
+	defer shutdownMu.Unlock()
+	shuttingDown = true
+}
+
+// Shutdown calls the registered functions in the reverse order of registration,
+// all of them are called even when one fails, and the first error is returned.
+func Shutdown(ctx context.Context) error {
+	StartShutdown()
+	shutdownMu.Lock()
+	hooks := shutdownHooks
+	shutdownMu.Unlock()
+
+	var first error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].fn(ctx); err != nil && first == nil {
+			first = fmt.Errorf("Failed to shutdown %s: %s", hooks[i].name, err)
+		}
+	}
+	return first
+}
+
+// ShuttingDown reports if the application started shutting down.
+func ShuttingDown() bool {
+	shutdownMu.Lock()
+	defer shutdownMu.Unlock()
+	return shuttingDown
+}
@@ -0,0 +1,12 @@
+package app
+
+// Build information, set at build time with
+// go build -ldflags "-X github.com/lucasfloriani/go-mongo/app.Version=1.0.0 -X ..."
+var (
+	// Version is the released version of the application
+	Version = "dev"
+	// Commit is the git commit the application was built from
+	Commit = "unknown"
+	// BuildTime is when the application was built
+	BuildTime = "unknown"
+)
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	workersMu sync.Mutex
+	// workers are the background workers started by Go, with the error that stopped them
+	workers = map[string]error{}
+)
+
+// errWorkerRunning marks the workers that are still running
+var errWorkerRunning = errors.New("running")
+
+// Go runs fn in a goroutine as the background worker with the given name, reported by
+// StoppedWorkers when it returns or panics before the application shuts down.
+func Go(name string, fn func() error) {
+	workersMu.Lock()
+	workers[name] = errWorkerRunning
+	workersMu.Unlock()
+
+	go func() {
+		err := errors.New("returned")
+		defer func() {
+			if p := recover(); p != nil {
+				err = fmt.Errorf("panic: %v", p)
+				Logger("app").Error("worker failed", "worker", name, "error", err)
+			}
+			workersMu.Lock()
+			workers[name] = err
+			workersMu.Unlock()
+		}()
+		if werr := fn(); werr != nil {
+			err = werr
+		}
+	}()
+}
+
+// StoppedWorkers returns the names of the background workers that stopped
+// while the application wasn't shutting down, with the reason.
+func StoppedWorkers() []string {
+	if ShuttingDown() {
+		return nil
+	}
+	workersMu.Lock()
+	defer workersMu.Unlock()
+	var stopped []string
+	for name, err := range workers {
+		if err != errWorkerRunning {
+			stopped = append(stopped, name+" ("+err.Error()+")")
+		}
+	}
+	sort.Strings(stopped)
+	return stopped
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/lucasfloriani/go-mongo/app"
+	"github.com/lucasfloriani/go-mongo/helper"
+
+	"github.com/labstack/echo"
+)
+
+// APIKeyHeader is the header used by machine-to-machine clients to send their API key
+const APIKeyHeader = "X-API-Key"
+
+// ErrInvalidAPIKey is returned when the API key doesn't exist, was revoked or is expired
+var ErrInvalidAPIKey = errors.New("Chave de API inválida.")
+
+// errAuthentication is returned when the API key can't be checked, hiding the cause from the caller
+var errAuthentication = errors.New("Não foi possível verificar a chave de API.")
+
+// apiKeyAuthenticator specifies the interface needed by the API key middleware to identify a caller.
+type apiKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (app.Identity, error)
+}
+
+// APIKey returns a middleware that identifies the caller by the X-API-Key header.
+// Requests without the header keep the anonymous identity, invalid keys are rejected with 401
+// and the failures to check the key with 500.
+func APIKey(authenticator apiKeyAuthenticator) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(APIKeyHeader)
+			if key == "" {
+				return next(c)
+			}
+			identity, err := authenticator.Authenticate(c.Request().Context(), key)
+			if err == ErrInvalidAPIKey {
+				return c.JSON(http.StatusUnauthorized, helper.NewRequestErrorResponse(c, ErrInvalidAPIKey))
+			}
+			if err != nil {
+				app.GetRequestScope(c).Logger().Error("api key authentication failed", "error", err)
+				return c.JSON(http.StatusInternalServerError, helper.NewRequestErrorResponse(c, errAuthentication))
+			}
+			app.GetRequestScope(c).SetIdentity(identity)
+			return next(c)
+		}
+	}
+}
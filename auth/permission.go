@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Permission represents an action that can be granted to a role.
+// Permissions ending with ":self" are only granted over records owned by the caller.
+type Permission string
+
+const (
+	// UserRead allows reading any user
+	UserRead Permission = "user:read"
+	// UserCreate allows creating users
+	UserCreate Permission = "user:create"
+	// UserUpdate allows updating any user
+	UserUpdate Permission = "user:update"
+	// UserDelete allows deleting any user
+	UserDelete Permission = "user:delete"
+	// UserLookup allows finding the users by CPF or email
+	UserLookup Permission = "user:lookup"
+	// CourseRead allows reading courses
+	CourseRead Permission = "course:read"
+	// CourseCreate allows creating courses
+	CourseCreate Permission = "course:create"
+	// CourseUpdate allows updating courses
+	CourseUpdate Permission = "course:update"
+	// CourseDelete allows deleting courses
+	CourseDelete Permission = "course:delete"
+	// APIKeyManage allows creating, listing, revoking and rotating API keys
+	APIKeyManage Permission = "apikey:manage"
+	// StatusRead allows reading the detailed status of the application
+	StatusRead Permission = "status:read"
+	// ReportRead allows reading the aggregated reports of the users
+	ReportRead Permission = "report:read"
+
+	// wildcard grants every permission
+	wildcard = "*"
+	// selfSuffix restricts a permission to the records owned by the caller
+	selfSuffix = ":self"
+)
+
+// Permissions lists every permission known by the application
+var Permissions = []Permission{
+	UserRead, UserCreate, UserUpdate, UserDelete, UserLookup,
+	CourseRead, CourseCreate, CourseUpdate, CourseDelete,
+	APIKeyManage, StatusRead, ReportRead,
+}
+
+// IsPermission check if the value is a known permission, including the ones restricted to the caller
+func IsPermission(value string) bool {
+	for _, p := range Permissions {
+		if Permission(value) == p || Permission(value) == p.Self() {
+			return true
+		}
+	}
+	return false
+}
+
+// Self returns the permission restricted to the records owned by the caller.
+func (p Permission) Self() Permission {
+	return p + selfSuffix
+}
+
+// PermissionError is returned when the caller doesn't have the permission needed by an action.
+type PermissionError struct {
+	Permission Permission
+}
+
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("Permissão necessária: %s.", e.Permission)
+}
+
+// StatusCode returns the HTTP status code that represents the error.
+func (e *PermissionError) StatusCode() int {
+	return http.StatusForbidden
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"strings"
+
+	"github.com/lucasfloriani/go-mongo/app"
+)
+
+// Authorize check if the caller of the request has the given permission.
+func Authorize(rs app.RequestScope, permission Permission) error {
+	if !granted(rs.Identity(), permission) {
+		return &PermissionError{permission}
+	}
+	return nil
+}
+
+// AuthorizeOwner check if the caller of the request has the given permission
+// or, when ownerID is the caller itself, the permission restricted to itself.
+func AuthorizeOwner(rs app.RequestScope, permission Permission, ownerID string) error {
+	identity := rs.Identity()
+	if granted(identity, permission) {
+		return nil
+	}
+	if identity.ID != "" && identity.ID == ownerID && granted(identity, permission.Self()) {
+		return nil
+	}
+	return &PermissionError{permission}
+}
+
+// AuthorizeScopes check if the caller of the request holds every scope it delegates,
+// so it can't create credentials more powerful than its own. A scope restricted to the
+// records owned by the caller is also held by the callers with the unrestricted permission.
+func AuthorizeScopes(rs app.RequestScope, scopes []string) error {
+	identity := rs.Identity()
+	for _, scope := range scopes {
+		permission := Permission(scope)
+		if granted(identity, permission) {
+			continue
+		}
+		if strings.HasSuffix(scope, selfSuffix) && granted(identity, Permission(strings.TrimSuffix(scope, selfSuffix))) {
+			continue
+		}
+		return &PermissionError{permission}
+	}
+	return nil
+}
+
+// granted check if the identity scopes or, when it has none, the identity role
+// declared in config contains the permission
+func granted(identity app.Identity, permission Permission) bool {
+	permissions := identity.Scopes
+	if permissions == nil {
+		permissions = app.Current().Authorization.Roles[identity.Role]
+	}
+	for _, p := range permissions {
+		if p == wildcard || Permission(p) == permission {
+			return true
+		}
+	}
+	return false
+}
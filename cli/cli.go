@@ -0,0 +1,145 @@
+// Package cli implements the subcommands of the go-mongo binary.
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/lucasfloriani/go-mongo/app"
+	"github.com/lucasfloriani/go-mongo/db"
+
+	"github.com/mongodb/mongo-go-driver/mongo"
+)
+
+// command is a subcommand of the binary
+type command struct {
+	// usage describes the arguments and what the command does
+	usage string
+	// args is the number of arguments required after the name of the command
+	args int
+	// database connects to the database before running the command
+	database bool
+	// run runs the command, returning the exit code
+	run func(env *env, args []string) int
+}
+
+// env is what the commands need to run
+type env struct {
+	ctx      context.Context
+	database *mongo.Database
+}
+
+// commands are the subcommands of the binary by name
+var commands = map[string]command{
+	"serve":           {usage: "runs the server (default)", database: true, run: serve},
+	"seed":            {usage: "<file> creates the users and courses of a YAML or JSON fixture", args: 1, database: true, run: seed},
+	"config validate": {usage: "validates the configuration", run: validateConfig},
+	"config print":    {usage: "prints the configuration with the secrets masked", run: printConfig},
+	"user get":        {usage: "<id> prints an user", args: 1, database: true, run: getUser},
+	"user create":     {usage: "<file> creates the user of a YAML or JSON file (- for stdin)", args: 1, database: true, run: createUser},
+	"user delete":     {usage: "<id> deletes an user", args: 1, database: true, run: deleteUser},
+	"course list":     {usage: "lists the courses, paged with --offset and --limit", database: true, run: listCourses},
+	"course import":   {usage: "<file> creates the courses of a YAML or JSON list (- for stdin)", args: 1, database: true, run: importCourses},
+	"indexes sync":    {usage: "creates the missing indexes declared by the DAOs", database: true, run: syncIndexes},
+	"indexes diff":    {usage: "prints the difference between the declared and the existing indexes", database: true, run: diffIndexes},
+	"migrate up":      {usage: "applies the pending migrations", database: true, run: migrateUp},
+	"migrate down":    {usage: "reverts the last applied migration", database: true, run: migrateDown},
+	"migrate status":  {usage: "lists the applied and pending migrations", database: true, run: migrateStatus},
+	"migrate create":  {usage: "<name> writes a new migration", args: 1, run: createMigration},
+}
+
+func init() {
+	app.Flags.Int("offset", 0, "offset of the listed items")
+	app.Flags.Int("limit", 0, "number of listed items, the default page size when 0")
+	app.Flags.Usage = usage
+}
+
+// Run parses the flags, loads the configuration and runs the subcommand named by the arguments,
+// returning the exit code. The server is started when no subcommand is given.
+func Run(arguments []string) int {
+	app.Flags.Parse(arguments)
+	name, cmd, args, ok := lookup(app.Flags.Args())
+	if !ok {
+		usage()
+		return 2
+	}
+
+	configDir, _ := app.Flags.GetString("config")
+	if err := app.LoadConfig(configDir); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid application configuration: %s\n", err)
+		return 1
+	}
+	app.InitLogger()
+
+	e := &env{ctx: context.Background()}
+	if cmd.database && app.Config.Storage == "memory" {
+		// The memory storage lives in the server, only serve can use it
+		if name != "serve" {
+			return fail(fmt.Errorf("The command %q requires the mongo storage", name))
+		}
+		cmd.database = false
+	}
+	if cmd.database {
+		client, database, err := db.Connect(e.ctx)
+		if err != nil {
+			app.Logger("main").Error("failed to connect to the database", "error", err)
+			return 1
+		}
+		defer client.Disconnect(e.ctx)
+		e.database = database
+	}
+
+	app.Logger("main").Debug("running command", "command", name)
+	return cmd.run(e, args)
+}
+
+// lookup finds the command named by the first one or two arguments, checking its arguments
+func lookup(args []string) (string, command, []string, bool) {
+	if len(args) == 0 {
+		return "serve", commands["serve"], nil, true
+	}
+	for _, n := range []int{2, 1} {
+		if len(args) < n {
+			continue
+		}
+		name := strings.Join(args[:n], " ")
+		if cmd, ok := commands[name]; ok {
+			return name, cmd, args[n:], len(args[n:]) == cmd.args
+		}
+	}
+	return "", command{}, nil, false
+}
+
+// usage prints the commands and the flags
+func usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, "Usage: go-mongo [flags] <command> [arguments]")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", name, commands[name].usage)
+	}
+	fmt.Fprintln(os.Stderr, "\nFlags:")
+	fmt.Fprint(os.Stderr, app.Flags.FlagUsages())
+}
+
+// fail prints the error, returning the exit code of failed commands
+func fail(err error) int {
+	fmt.Fprintln(os.Stderr, err)
+	return 1
+}
+
+// newScope creates the scope used to call the services, granted every permission
+// since the operator already has access to the configuration and the database
+func newScope(ctx context.Context) app.RequestScope {
+	rs := app.NewRequestScope(ctx, "cli")
+	rs.SetIdentity(app.Identity{ID: "cli", Scopes: []string{"*"}})
+	return rs
+}
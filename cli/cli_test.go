@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		args []string
+		name string
+		rest []string
+		ok   bool
+	}{
+		{nil, "serve", nil, true},
+		{[]string{"serve"}, "serve", nil, true},
+		{[]string{"config", "validate"}, "config validate", nil, true},
+		{[]string{"user", "get", "5f1a2b3c4d5e6f7a8b9c0d1e"}, "user get", []string{"5f1a2b3c4d5e6f7a8b9c0d1e"}, true},
+		{[]string{"seed", "fixture.yaml"}, "seed", []string{"fixture.yaml"}, true},
+		{[]string{"user", "get"}, "user get", nil, false},
+		{[]string{"config", "validate", "extra"}, "config validate", []string{"extra"}, false},
+		{[]string{"seed"}, "seed", nil, false},
+		{[]string{"user"}, "", nil, false},
+		{[]string{"unknown", "command"}, "", nil, false},
+	}
+	for _, test := range tests {
+		t.Run(strings.Join(test.args, " "), func(t *testing.T) {
+			name, _, rest, ok := lookup(test.args)
+			if name != test.name || strings.Join(rest, " ") != strings.Join(test.rest, " ") || ok != test.ok {
+				t.Errorf("lookup(%q) = %q, %q, %v, want %q, %q, %v", test.args, name, rest, ok, test.name, test.rest, test.ok)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	migrations := t.TempDir()
+	t.Setenv("GOMONGO_STORAGE", "memory")
+	t.Setenv("GOMONGO_DATABASE_MIGRATIONS_DIRECTORY", migrations)
+
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"valid configuration", []string{"config", "validate"}, 0},
+		{"print configuration", []string{"config", "print"}, 0},
+		{"create migration", []string{"migrate", "create", "add_field"}, 0},
+		{"unknown command", []string{"unknown"}, 2},
+		{"missing argument", []string{"user", "get"}, 2},
+		{"extra argument", []string{"config", "validate", "extra"}, 2},
+		{"unknown environment", []string{"-e", "nowhere", "config", "validate"}, 1},
+		{"missing configuration", []string{"-c", t.TempDir(), "config", "validate"}, 1},
+		{"database command on memory storage", []string{"user", "get", "5f1a2b3c4d5e6f7a8b9c0d1e"}, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// The flags keep their values between runs, so every run sets them
+			args := append([]string{"-c", "../config", "-e", "test"}, test.args...)
+			if code := Run(args); code != test.code {
+				t.Errorf("Run(%q) = %d, want %d", args, code, test.code)
+			}
+		})
+	}
+
+	files, _ := filepath.Glob(filepath.Join(migrations, "*_add_field.go"))
+	if len(files) != 1 {
+		t.Errorf("migrate create wrote %v, want one *_add_field.go file", files)
+	}
+}
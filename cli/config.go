@@ -0,0 +1,25 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/lucasfloriani/go-mongo/app"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// validateConfig reports the configuration as valid, since Run already loaded and validated it
+func validateConfig(e *env, args []string) int {
+	fmt.Printf("The configuration of the environment %q is valid\n", app.Config.Environment)
+	return 0
+}
+
+// printConfig prints the configuration as YAML with the secrets masked
+func printConfig(e *env, args []string) int {
+	out, err := yaml.Marshal(app.Settings())
+	if err != nil {
+		return fail(err)
+	}
+	fmt.Print(string(out))
+	return 0
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/lucasfloriani/go-mongo/app"
+	"github.com/lucasfloriani/go-mongo/dao"
+	"github.com/lucasfloriani/go-mongo/helper"
+	"github.com/lucasfloriani/go-mongo/model"
+	"github.com/lucasfloriani/go-mongo/service"
+)
+
+// listCourses prints the courses of the page selected by --offset and --limit
+func listCourses(e *env, args []string) int {
+	offset, _ := app.Flags.GetInt("offset")
+	limit, _ := app.Flags.GetInt("limit")
+	if limit <= 0 {
+		limit = app.Current().Pagination.DefaultPageSize
+	}
+	if limit <= 0 {
+		limit = helper.DefaultPageSize
+	}
+	courses, err := service.NewCourseService(dao.NewCourseDAO(e.database)).Query(newScope(e.ctx), offset, limit)
+	if err != nil {
+		return fail(err)
+	}
+	return printJSON(courses)
+}
+
+// importCourses creates the courses of the given list, stopping on the first invalid one
+func importCourses(e *env, args []string) int {
+	var courses []model.Course
+	if err := decode(args[0], &courses); err != nil {
+		return fail(err)
+	}
+
+	rs := newScope(e.ctx)
+	courseService := service.NewCourseService(dao.NewCourseDAO(e.database))
+	for i := range courses {
+		if _, err := courseService.Create(rs, &courses[i]); err != nil {
+			return fail(fmt.Errorf("course %d: %s (%d imported)", i+1, err, i))
+		}
+	}
+
+	fmt.Printf("%d courses imported\n", len(courses))
+	return 0
+}
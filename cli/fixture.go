@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/lucasfloriani/go-mongo/model"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// fixture is the content of the files loaded by seed
+type fixture struct {
+	Courses []model.Course `json:"courses"`
+	Users   []model.User   `json:"users"`
+}
+
+// decode reads the YAML or JSON file (or stdin when path is "-") into v, using the json
+// tags of the models for both formats
+func decode(path string, v interface{}) error {
+	var (
+		content []byte
+		err     error
+	)
+	if path == "-" {
+		content, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		content, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return err
+	}
+
+	// YAML is a superset of JSON, so both are parsed as YAML and converted to JSON
+	var document interface{}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return fmt.Errorf("Invalid file %s: %s", path, err)
+	}
+	content, err = json.Marshal(jsonValue(document))
+	if err != nil {
+		return fmt.Errorf("Invalid file %s: %s", path, err)
+	}
+	if err := json.Unmarshal(content, v); err != nil {
+		return fmt.Errorf("Invalid file %s: %s", path, err)
+	}
+	return nil
+}
+
+// jsonValue converts the maps decoded from YAML, keyed by interface{}, to maps keyed by string
+func jsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, elem := range v {
+			m[fmt.Sprint(key)] = jsonValue(elem)
+		}
+		return m
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = jsonValue(elem)
+		}
+	}
+	return value
+}
+
+// printJSON prints v as indented JSON, like the responses of the API
+func printJSON(v interface{}) int {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fail(err)
+	}
+	fmt.Println(string(out))
+	return 0
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lucasfloriani/go-mongo/dao"
+)
+
+// syncIndexes creates the missing indexes and recreates the changed ones, dropping the unexpected ones when
+// database.indexes.drop_unexpected is set, and prints the differences found
+func syncIndexes(e *env, args []string) int {
+	diffs, err := dao.SyncIndexes(e.ctx, e.database)
+	if err != nil {
+		return fail(err)
+	}
+	for _, diff := range diffs {
+		if !diff.Empty() {
+			fmt.Println(diff)
+		}
+	}
+	return 0
+}
+
+// diffIndexes prints the indexes to create (+), to recreate (-+) and to drop (-),
+// returning the exit code: 0 when they match the declared ones, 1 when they don't
+func diffIndexes(e *env, args []string) int {
+	diffs, err := dao.DiffIndexes(e.ctx, e.database)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	code := 0
+	for _, diff := range diffs {
+		if !diff.Empty() {
+			fmt.Println(diff)
+			code = 1
+		}
+	}
+	return code
+}
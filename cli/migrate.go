@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lucasfloriani/go-mongo/app"
+	"github.com/lucasfloriani/go-mongo/migration"
+)
+
+// migrateUp applies the pending migrations
+func migrateUp(e *env, args []string) int {
+	if err := migration.NewRunner(e.database).Up(e.ctx); err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+// migrateDown reverts the last applied migration
+func migrateDown(e *env, args []string) int {
+	if err := migration.NewRunner(e.database).Down(e.ctx); err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+// migrateStatus prints the version, when it was applied and the name of every migration
+func migrateStatus(e *env, args []string) int {
+	list, err := migration.NewRunner(e.database).Status(e.ctx)
+	if err != nil {
+		return fail(err)
+	}
+	for _, status := range list {
+		applied := "pending"
+		if status.Applied() {
+			applied = status.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Printf("%d\t%s\t%s\n", status.Version, applied, status.Name)
+	}
+	return 0
+}
+
+// createMigration writes a new migration, which doesn't need the database
+func createMigration(e *env, args []string) int {
+	path, err := migration.Create(app.Config.Database.Migrations.Directory, args[0])
+	if err != nil {
+		return fail(err)
+	}
+	fmt.Println(path)
+	return 0
+}
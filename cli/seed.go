@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/lucasfloriani/go-mongo/dao"
+	"github.com/lucasfloriani/go-mongo/service"
+)
+
+// seed creates the courses and then the users of a fixture file through the services,
+// so they are validated like the ones created by the API
+func seed(e *env, args []string) int {
+	var f fixture
+	if err := decode(args[0], &f); err != nil {
+		return fail(err)
+	}
+
+	rs := newScope(e.ctx)
+	courseService := service.NewCourseService(dao.NewCourseDAO(e.database))
+	for i := range f.Courses {
+		if _, err := courseService.Create(rs, &f.Courses[i]); err != nil {
+			return fail(fmt.Errorf("course %d: %s", i+1, err))
+		}
+	}
+	userService := service.NewUserService(dao.NewUserDAO(e.database))
+	for i := range f.Users {
+		if _, err := userService.Create(rs, &f.Users[i]); err != nil {
+			return fail(fmt.Errorf("user %d: %s", i+1, err))
+		}
+	}
+
+	fmt.Printf("%d courses and %d users created\n", len(f.Courses), len(f.Users))
+	return 0
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/lucasfloriani/go-mongo/app"
+	"github.com/lucasfloriani/go-mongo/dao"
+	"github.com/lucasfloriani/go-mongo/migration"
+	"github.com/lucasfloriani/go-mongo/router"
+)
+
+// serve runs the server until SIGINT or SIGTERM
+func serve(e *env, args []string) int {
+	logger := app.Logger("main")
+	app.Subscribe("log", app.InitLogger)
+	if err := app.WatchConfig(); err != nil {
+		logger.Error("failed to watch the configuration", "error", err)
+	}
+
+	// Applies the pending migrations before the indexes are synchronized, since the declared
+	// indexes may depend on the migrated documents. Replicas starting together wait for the one
+	// holding the lock
+	if e.database != nil && app.Config.Database.Migrations.Auto {
+		if err := migration.NewRunner(e.database).UpWhenUnlocked(e.ctx); err != nil {
+			logger.Error("failed to apply the migrations", "error", err)
+			return 1
+		}
+	}
+
+	// Synchronizes the indexes declared by the DAOs, unless the memory storage is used
+	if e.database != nil && app.Config.Database.Indexes.Sync {
+		if _, err := dao.SyncIndexes(e.ctx, e.database); err != nil {
+			logger.Error("failed to sync the indexes", "error", err)
+			return 1
+		}
+	}
+
+	// Runs the server
+	routers := router.Setup(e.database)
+	failed := make(chan error, 1)
+	go func() {
+		logger.Info("server started", "port", app.Config.ServerPort, "environment", app.Config.Environment)
+		if err := routers.Start(fmt.Sprintf(":%v", app.Config.ServerPort)); err != nil && err != http.ErrServerClosed {
+			failed <- err
+		}
+	}()
+
+	// Waits for the stop signal
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case sig := <-quit:
+		logger.Info("shutting down", "signal", sig.String())
+	case err := <-failed:
+		logger.Error("server failed", "error", err)
+		return 1
+	}
+
+	// Reports the shutdown in /readyz while the load balancers stop routing to this instance,
+	// then stops accepting connections and drains the in-flight requests, background jobs
+	// and buffered data. The database is disconnected by Run afterwards
+	app.StartShutdown()
+	time.Sleep(app.Config.ShutdownDelay)
+	ctx, cancel := context.WithTimeout(context.Background(), app.Config.ShutdownTimeout)
+	defer cancel()
+	code := 0
+	if err := routers.Shutdown(ctx); err != nil {
+		logger.Error("shutdown failed", "error", err)
+		code = 1
+	}
+	if err := app.Shutdown(ctx); err != nil {
+		logger.Error("shutdown failed", "error", err)
+		code = 1
+	}
+	return code
+}
@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"github.com/lucasfloriani/go-mongo/dao"
+	"github.com/lucasfloriani/go-mongo/model"
+	"github.com/lucasfloriani/go-mongo/service"
+)
+
+// getUser prints the user with the given ID
+func getUser(e *env, args []string) int {
+	user, err := service.NewUserService(dao.NewUserDAO(e.database)).Get(newScope(e.ctx), args[0])
+	if err != nil {
+		return fail(err)
+	}
+	return printJSON(user)
+}
+
+// createUser creates the user of the given file, printing it with its ID
+func createUser(e *env, args []string) int {
+	user := model.NewUser()
+	if err := decode(args[0], user); err != nil {
+		return fail(err)
+	}
+	user, err := service.NewUserService(dao.NewUserDAO(e.database)).Create(newScope(e.ctx), user)
+	if err != nil {
+		return fail(err)
+	}
+	return printJSON(user)
+}
+
+// deleteUser deletes the user with the given ID, printing it
+func deleteUser(e *env, args []string) int {
+	user, err := service.NewUserService(dao.NewUserDAO(e.database)).Delete(newScope(e.ctx), args[0])
+	if err != nil {
+		return fail(err)
+	}
+	return printJSON(user)
+}
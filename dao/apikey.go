@@ -0,0 +1,173 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	mongodb "github.com/lucasfloriani/go-mongo/db"
+	"github.com/lucasfloriani/go-mongo/model"
+	"github.com/mongodb/mongo-go-driver/bson"
+	"github.com/mongodb/mongo-go-driver/bson/objectid"
+	"github.com/mongodb/mongo-go-driver/mongo"
+	"github.com/mongodb/mongo-go-driver/mongo/findopt"
+)
+
+// APIKeyDAO persists API key data in database, contains methods for each CRUD actions.
+type APIKeyDAO struct {
+	db *mongo.Collection
+}
+
+// NewAPIKeyDAO creates a new APIKeyDAO
+func NewAPIKeyDAO(db *mongo.Database) *APIKeyDAO {
+	return &APIKeyDAO{db.Collection("apikey", mongodb.CollectionOptions("apikey")...)}
+}
+
+func (dao *APIKeyDAO) collection() *mongo.Collection {
+	return dao.db
+}
+
+// Indexes returns the indexes of the API key collection.
+func (dao *APIKeyDAO) Indexes() []Index {
+	return []Index{
+		{
+			Name:   "hash_1",
+			Keys:   bson.NewDocument(bson.EC.Int32("hash", 1)),
+			Unique: true,
+		},
+		{
+			Name:          "owner_1_active",
+			Keys:          bson.NewDocument(bson.EC.Int32("owner", 1)),
+			PartialFilter: bson.NewDocument(bson.EC.Boolean("revoked", false)),
+		},
+	}
+}
+
+func (dao *APIKeyDAO) filter(offset, limit int) []findopt.Find {
+	var elems []findopt.Find
+
+	elems = append(elems, findopt.Limit(int64(limit)), findopt.Skip(int64(offset)))
+
+	return elems
+}
+
+// All retrieves the API key records with the specified offset and limit from the database.
+func (dao *APIKeyDAO) All(ctx context.Context, offset, limit int) (elements []model.APIKey, err error) {
+	cur, err := dao.db.Find(ctx, nil, dao.filter(offset, limit)...)
+	if err != nil {
+		return
+	}
+	defer cur.Close(ctx)
+
+	var elem model.APIKey
+	for cur.Next(ctx) {
+		if err = cur.Decode(&elem); err != nil {
+			return
+		}
+		elements = append(elements, elem)
+	}
+
+	return
+}
+
+// Count returns the number of the API key records in the database.
+func (dao *APIKeyDAO) Count(ctx context.Context) (int, error) {
+	count, err := dao.db.Count(ctx, nil)
+	return int(count), err
+}
+
+// Get reads the API key with the specified ID from the database.
+func (dao *APIKeyDAO) Get(ctx context.Context, id string) (*model.APIKey, error) {
+	objID, err := objectid.FromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	k := model.NewAPIKey()
+	err = dao.db.FindOne(
+		ctx,
+		bson.NewDocument(
+			bson.EC.ObjectID("_id", objID),
+		),
+	).Decode(k)
+	return k, err
+}
+
+// GetByHash reads the API key with the specified hash from the database.
+func (dao *APIKeyDAO) GetByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	k := model.NewAPIKey()
+	err := dao.db.FindOne(
+		ctx,
+		bson.NewDocument(
+			bson.EC.String("hash", hash),
+		),
+	).Decode(k)
+	return k, err
+}
+
+// Create saves a new API key record in the database.
+// The APIKey.Id field will be populated with an automatically generated ID upon successful saving.
+func (dao *APIKeyDAO) Create(ctx context.Context, k *model.APIKey) error {
+	res, err := dao.db.InsertOne(
+		ctx,
+		bson.NewDocument(
+			bson.EC.String("name", k.Name),
+			bson.EC.String("owner", k.Owner),
+			bson.EC.String("prefix", k.Prefix),
+			bson.EC.String("hash", k.Hash),
+			bson.EC.ArrayFromElements("scopes", dao.getScopes(k)...),
+			bson.EC.Time("expires_at", k.ExpiresAt),
+			bson.EC.Time("last_used_at", k.LastUsedAt),
+			bson.EC.Time("created_at", k.CreatedAt),
+			bson.EC.Boolean("revoked", k.Revoked),
+		),
+	)
+	if err != nil {
+		return err
+	}
+	k.ID = res.InsertedID.(objectid.ObjectID)
+	return nil
+}
+
+// Update saves the changes to an API key in the database.
+func (dao *APIKeyDAO) Update(ctx context.Context, k *model.APIKey) error {
+	_, err := dao.db.UpdateOne(
+		ctx,
+		bson.NewDocument(
+			bson.EC.ObjectID("_id", k.ID),
+		),
+		bson.NewDocument(
+			bson.EC.SubDocumentFromElements("$set",
+				bson.EC.String("name", k.Name),
+				bson.EC.String("prefix", k.Prefix),
+				bson.EC.String("hash", k.Hash),
+				bson.EC.ArrayFromElements("scopes", dao.getScopes(k)...),
+				bson.EC.Time("expires_at", k.ExpiresAt),
+				bson.EC.Boolean("revoked", k.Revoked),
+			),
+		),
+	)
+	return err
+}
+
+// Touch saves the last time the API key with the specified ID was used.
+func (dao *APIKeyDAO) Touch(ctx context.Context, k *model.APIKey, usedAt time.Time) error {
+	_, err := dao.db.UpdateOne(
+		ctx,
+		bson.NewDocument(
+			bson.EC.ObjectID("_id", k.ID),
+		),
+		bson.NewDocument(
+			bson.EC.SubDocumentFromElements("$set",
+				bson.EC.Time("last_used_at", usedAt),
+			),
+		),
+	)
+	return err
+}
+
+func (dao *APIKeyDAO) getScopes(k *model.APIKey) (elems []*bson.Value) {
+	for _, scope := range k.Scopes {
+		elems = append(elems, bson.VC.String(scope))
+	}
+
+	return
+}
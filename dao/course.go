@@ -3,7 +3,9 @@ package dao
 import (
 	"context"
 
+	mongodb "github.com/lucasfloriani/go-mongo/db"
 	"github.com/lucasfloriani/go-mongo/model"
+	"github.com/lucasfloriani/go-mongo/search"
 	"github.com/mongodb/mongo-go-driver/bson"
 	"github.com/mongodb/mongo-go-driver/bson/objectid"
 	"github.com/mongodb/mongo-go-driver/mongo"
@@ -17,7 +19,30 @@ type CourseDAO struct {
 
 // NewCourseDAO creates a new CourseDAO
 func NewCourseDAO(db *mongo.Database) *CourseDAO {
-	return &CourseDAO{db.Collection("course")}
+	return &CourseDAO{db.Collection("course", mongodb.CollectionOptions("course")...)}
+}
+
+func (dao *CourseDAO) collection() *mongo.Collection {
+	return dao.db
+}
+
+// Indexes returns the indexes of the course collection.
+func (dao *CourseDAO) Indexes() []Index {
+	return []Index{
+		{
+			Name: "name_1",
+			Keys: bson.NewDocument(bson.EC.Int32("name", 1)),
+		},
+		{
+			Name: "name_normalized_1",
+			Keys: bson.NewDocument(bson.EC.Int32("name_normalized", 1)),
+		},
+		{
+			Name:            "name_text",
+			Keys:            bson.NewDocument(bson.EC.String("name", "text")),
+			DefaultLanguage: textLanguage,
+		},
+	}
 }
 
 func (dao *CourseDAO) filter(offset, limit int) []findopt.Find {
@@ -29,56 +54,94 @@ func (dao *CourseDAO) filter(offset, limit int) []findopt.Find {
 }
 
 // All retrieves the course records with the specified offset and limit from the database.
-func (dao *CourseDAO) All(offset, limit int) (elements []model.Course, err error) {
-	cur, err := dao.db.Find(context.Background(), nil, dao.filter(offset, limit)...)
-	if err != nil {
-		return
-	}
-	defer cur.Close(context.Background())
+func (dao *CourseDAO) All(ctx context.Context, offset, limit int) (elements []model.Course, err error) {
+	ctx, op := newOperation(ctx, "course", "all", nil)
+	defer func() { op.done(err, len(elements)) }()
 
-	var elem model.Course
-	for cur.Next(context.Background()) {
-		if err = cur.Decode(&elem); err != nil {
-			return
+	err = retryRead(ctx, func() error {
+		elements = nil
+		cur, err := dao.db.Find(ctx, nil, dao.filter(offset, limit)...)
+		if err != nil {
+			return err
 		}
-		elements = append(elements, elem)
-	}
+		defer cur.Close(ctx)
+
+		var elem model.Course
+		for cur.Next(ctx) {
+			if err := cur.Decode(&elem); err != nil {
+				return err
+			}
+			elements = append(elements, elem)
+		}
+		return cur.Err()
+	})
 
 	return
 }
 
 // Count returns the number of the course records in the database.
-func (dao *CourseDAO) Count() (int, error) {
-	count, err := dao.db.Count(context.Background(), nil)
+func (dao *CourseDAO) Count(ctx context.Context) (int, error) {
+	ctx, op := newOperation(ctx, "course", "count", nil)
+	var count int64
+	err := retryRead(ctx, func() (err error) {
+		count, err = dao.db.Count(ctx, nil)
+		return
+	})
+	op.done(err, 0)
 	return int(count), err
 }
 
+// Search retrieves the courses matching the full-text query, by relevance, with the specified offset and limit.
+func (dao *CourseDAO) Search(ctx context.Context, query string, offset, limit int) ([]model.SearchResult, error) {
+	return findText(ctx, dao.db, "course", query, offset, limit, func(cur mongo.Cursor) (interface{}, error) {
+		var elem model.Course
+		err := cur.Decode(&elem)
+		return elem, err
+	})
+}
+
+// CountSearch returns the number of the courses matching the full-text query.
+func (dao *CourseDAO) CountSearch(ctx context.Context, query string) (int, error) {
+	return countText(ctx, dao.db, "course", query)
+}
+
+// Suggest retrieves the courses whose name starts with the prefix, ignoring case and diacritics,
+// by name up to the limit.
+func (dao *CourseDAO) Suggest(ctx context.Context, prefix string, limit int) ([]model.Suggestion, error) {
+	return suggest(ctx, dao.db, "course", prefix, limit)
+}
+
 // Get reads the course with the specified ID from the database.
-func (dao *CourseDAO) Get(id string) (*model.Course, error) {
+func (dao *CourseDAO) Get(ctx context.Context, id string) (*model.Course, error) {
 	objID, err := objectid.FromHex(id)
 	if err != nil {
 		return nil, err
 	}
+	filter := bson.NewDocument(
+		bson.EC.ObjectID("_id", objID),
+	)
+	ctx, op := newOperation(ctx, "course", "get", filter)
 	c := model.NewCourse()
-	err = dao.db.FindOne(
-		context.Background(),
-		bson.NewDocument(
-			bson.EC.ObjectID("_id", objID),
-		),
-	).Decode(c)
+	err = retryRead(ctx, func() error {
+		return dao.db.FindOne(ctx, filter).Decode(c)
+	})
+	op.done(err, documents(err))
 	return c, err
 }
 
 // Create saves a new course record in the database.
 // The Course.Id field will be populated with an automatically generated ID upon successful saving.
-func (dao *CourseDAO) Create(c *model.Course) error {
+func (dao *CourseDAO) Create(ctx context.Context, c *model.Course) error {
+	ctx, op := newOperation(ctx, "course", "create", nil)
 	res, err := dao.db.InsertOne(
-		context.Background(),
+		ctx,
 		bson.NewDocument(
 			bson.EC.String("name", c.Name),
+			bson.EC.String("name_normalized", search.Normalize(c.Name)),
 			bson.EC.String("link", c.Link),
 		),
 	)
+	op.done(err, 0)
 	if err != nil {
 		return err
 	}
@@ -87,29 +150,33 @@ func (dao *CourseDAO) Create(c *model.Course) error {
 }
 
 // Update saves the changes to an course in the database.
-func (dao *CourseDAO) Update(c *model.Course) error {
+func (dao *CourseDAO) Update(ctx context.Context, c *model.Course) error {
+	filter := bson.NewDocument(
+		bson.EC.ObjectID("_id", c.ID),
+	)
+	ctx, op := newOperation(ctx, "course", "update", filter)
 	_, err := dao.db.UpdateOne(
-		context.Background(),
-		bson.NewDocument(
-			bson.EC.ObjectID("_id", c.ID),
-		),
+		ctx,
+		filter,
 		bson.NewDocument(
 			bson.EC.SubDocumentFromElements("$set",
 				bson.EC.String("name", c.Name),
+				bson.EC.String("name_normalized", search.Normalize(c.Name)),
 				bson.EC.String("link", c.Link),
 			),
 		),
 	)
+	op.done(err, 0)
 	return err
 }
 
 // Delete deletes an course with the specified ID from the database.
-func (dao *CourseDAO) Delete(c *model.Course) error {
-	_, err := dao.db.DeleteOne(
-		context.Background(),
-		bson.NewDocument(
-			bson.EC.ObjectID("_id", c.ID),
-		),
+func (dao *CourseDAO) Delete(ctx context.Context, c *model.Course) error {
+	filter := bson.NewDocument(
+		bson.EC.ObjectID("_id", c.ID),
 	)
+	ctx, op := newOperation(ctx, "course", "delete", filter)
+	_, err := dao.db.DeleteOne(ctx, filter)
+	op.done(err, 0)
 	return err
 }
@@ -0,0 +1,359 @@
+// Package daotest checks that the DAOs of every storage behave the same way.
+//
+// The checks are run against a DAO, Mongo or memory, and return the behaviors that
+// differ from the expected ones, e.g. from a test:
+//
+//	if err := daotest.TestUserDAO(ctx, dao.NewMemoryUserDAO()); err != nil {
+//		t.Fatal(err)
+//	}
+//
+// The DAOs don't need to be empty, the checks only count and page over the
+// documents they create, which are deleted at the end.
+package daotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	mongodb "github.com/lucasfloriani/go-mongo/db"
+	"github.com/lucasfloriani/go-mongo/model"
+
+	"github.com/mongodb/mongo-go-driver/bson/objectid"
+	"github.com/mongodb/mongo-go-driver/mongo"
+)
+
+// UserDAO is the user DAO under check.
+type UserDAO interface {
+	All(ctx context.Context, offset, limit int) ([]model.User, error)
+	Count(ctx context.Context) (int, error)
+	Get(ctx context.Context, id string) (*model.User, error)
+	GetByCPF(ctx context.Context, cpf model.CPF) (*model.User, error)
+	GetByEmail(ctx context.Context, email string) (*model.User, error)
+	Create(ctx context.Context, u *model.User) error
+	Update(ctx context.Context, u *model.User) error
+	Delete(ctx context.Context, u *model.User) error
+}
+
+// CourseDAO is the course DAO under check.
+type CourseDAO interface {
+	All(ctx context.Context, offset, limit int) ([]model.Course, error)
+	Count(ctx context.Context) (int, error)
+	Search(ctx context.Context, query string, offset, limit int) ([]model.SearchResult, error)
+	CountSearch(ctx context.Context, query string) (int, error)
+	Get(ctx context.Context, id string) (*model.Course, error)
+	Create(ctx context.Context, c *model.Course) error
+	Update(ctx context.Context, c *model.Course) error
+	Delete(ctx context.Context, c *model.Course) error
+}
+
+// checker collects the failures of the checks
+type checker struct {
+	failures []error
+}
+
+func (c *checker) errorf(format string, args ...interface{}) {
+	c.failures = append(c.failures, fmt.Errorf(format, args...))
+}
+
+func (c *checker) err() error {
+	return errors.Join(c.failures...)
+}
+
+// crud is a DAO seen through its hex IDs, so users and courses share the checks
+type crud[T any] struct {
+	all    func(ctx context.Context, offset, limit int) ([]T, error)
+	count  func(ctx context.Context) (int, error)
+	get    func(ctx context.Context, id string) (*T, error)
+	create func(ctx context.Context, item *T) error
+	update func(ctx context.Context, item *T) error
+	delete func(ctx context.Context, item *T) error
+	id     func(item T) objectid.ObjectID
+	setID  func(item *T, id objectid.ObjectID)
+	// change returns the item with every field, except the ID, changed
+	change func(item T) T
+}
+
+// TestUserDAO checks the user DAO.
+func TestUserDAO(ctx context.Context, dao UserDAO) error {
+	courseID := objectid.New()
+	cpfs := []model.CPF{"52998224725", "11144477735", "12345678909"}
+	fixtures := make([]model.User, 3)
+	for i := range fixtures {
+		fixtures[i] = model.User{
+			Name:  fmt.Sprintf("Usuário %d", i+1),
+			Age:   uint(20 + i),
+			CPF:   cpfs[i],
+			Email: fmt.Sprintf("usuario%d@example.com", i+1),
+			Address: model.Address{
+				Street:  "Rua das Flores",
+				Number:  fmt.Sprint(i + 1),
+				City:    "São Paulo",
+				State:   "SP",
+				CEP:     "01001-000",
+				Country: "Brasil",
+			},
+			Phones:  []model.Phone{{Number: fmt.Sprintf("+551191234567%d", i), Type: model.PhoneMobile, Primary: true, Country: "BR"}},
+			Courses: []model.Course{{ID: courseID, Name: "Curso de Go", Link: "https://example.com/go"}},
+		}
+	}
+	err := run(ctx, "user", fixtures, crud[model.User]{
+		all:    dao.All,
+		count:  dao.Count,
+		get:    dao.Get,
+		create: dao.Create,
+		update: dao.Update,
+		delete: dao.Delete,
+		id:     func(u model.User) objectid.ObjectID { return u.ID },
+		setID:  func(u *model.User, id objectid.ObjectID) { u.ID = id },
+		change: func(u model.User) model.User {
+			u.Name += " alterado"
+			u.Age++
+			u.CPF = "39053344705"
+			u.Email = "alterado." + u.Email
+			u.Address.Complement = "Apto 12"
+			u.Phones = append(u.Phones, model.Phone{Number: "+5521998765432", Type: model.PhoneWhatsApp, Country: "BR"})
+			u.Courses = []model.Course{{ID: objectid.New(), Name: "Curso de Mongo", Link: "https://example.com/mongo"}}
+			return u
+		},
+	})
+	return errors.Join(err, checkIdentity(ctx, dao))
+}
+
+// checkIdentity checks the lookups by CPF and email, and that both are unique among the users
+// but optional
+func checkIdentity(ctx context.Context, dao UserDAO) error {
+	c := &checker{}
+	var created []*model.User
+	create := func(cpf model.CPF, email string) (*model.User, error) {
+		u := &model.User{Name: "Usuário identificado", CPF: cpf, Email: email}
+		err := dao.Create(ctx, u)
+		if err == nil {
+			created = append(created, u)
+		}
+		return u, err
+	}
+	defer func() {
+		for _, u := range created {
+			dao.Delete(ctx, u)
+		}
+	}()
+
+	u, err := create("86288366757", "identificado@example.com")
+	if err != nil {
+		return fmt.Errorf("user: Create: %s", err)
+	}
+	if got, err := dao.GetByCPF(ctx, u.CPF); err != nil || got.ID != u.ID {
+		c.errorf("user: GetByCPF returned %+v, %v, want the user with the CPF", got, err)
+	}
+	if got, err := dao.GetByEmail(ctx, u.Email); err != nil || got.ID != u.ID {
+		c.errorf("user: GetByEmail returned %+v, %v, want the user with the email", got, err)
+	}
+	if _, err := dao.GetByCPF(ctx, "71428793860"); err != mongo.ErrNoDocuments {
+		c.errorf("user: GetByCPF with an unknown CPF returned %v, want mongo.ErrNoDocuments", err)
+	}
+	if _, err := dao.GetByEmail(ctx, "desconhecido@example.com"); err != mongo.ErrNoDocuments {
+		c.errorf("user: GetByEmail with an unknown email returned %v, want mongo.ErrNoDocuments", err)
+	}
+
+	// Duplicates are rejected with the duplicate key error of Mongo
+	if _, err := create(u.CPF, "outro@example.com"); !mongodb.IsDuplicateKey(err) {
+		c.errorf("user: Create with a duplicate CPF returned %v, want a duplicate key error", err)
+	}
+	if _, err := create("71428793860", u.Email); !mongodb.IsDuplicateKey(err) {
+		c.errorf("user: Create with a duplicate email returned %v, want a duplicate key error", err)
+	}
+
+	// Users without CPF and email don't conflict
+	other, err := create("", "")
+	if err != nil {
+		c.errorf("user: Create without CPF and email: %s", err)
+	} else if _, err := create("", ""); err != nil {
+		c.errorf("user: Create of a second user without CPF and email: %s", err)
+	} else {
+		other.Email = u.Email
+		if err := dao.Update(ctx, other); !mongodb.IsDuplicateKey(err) {
+			c.errorf("user: Update to a duplicate email returned %v, want a duplicate key error", err)
+		}
+	}
+
+	return c.err()
+}
+
+// TestCourseDAO checks the course DAO.
+func TestCourseDAO(ctx context.Context, dao CourseDAO) error {
+	fixtures := make([]model.Course, 3)
+	for i := range fixtures {
+		fixtures[i] = model.Course{
+			Name: fmt.Sprintf("Curso %d", i+1),
+			Link: fmt.Sprintf("https://example.com/%d", i+1),
+		}
+	}
+	err := run(ctx, "course", fixtures, crud[model.Course]{
+		all:    dao.All,
+		count:  dao.Count,
+		get:    dao.Get,
+		create: dao.Create,
+		update: dao.Update,
+		delete: dao.Delete,
+		id:     func(c model.Course) objectid.ObjectID { return c.ID },
+		setID:  func(c *model.Course, id objectid.ObjectID) { c.ID = id },
+		change: func(c model.Course) model.Course {
+			c.Name += " alterado"
+			c.Link += "/alterado"
+			return c
+		},
+	})
+	return errors.Join(err, checkSearch(ctx, dao))
+}
+
+// checkSearch checks that the full-text search matches the whole words of the name, ignoring
+// case and diacritics. The relevance of the Mongo text indexes and of the memory DAOs differ,
+// so only the courses found are compared
+func checkSearch(ctx context.Context, dao CourseDAO) error {
+	c := &checker{}
+	courses := []model.Course{
+		{Name: "Xilografia Avançada", Link: "https://example.com/xilografia"},
+		{Name: "Marcenaria", Link: "https://example.com/marcenaria"},
+		{Name: "Técnicas de xilografia e gravura", Link: "https://example.com/tecnicas"},
+	}
+	defer func() {
+		for i := range courses {
+			dao.Delete(ctx, &courses[i])
+		}
+	}()
+	for i := range courses {
+		if err := dao.Create(ctx, &courses[i]); err != nil {
+			return fmt.Errorf("course: Create: %s", err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{"XILOGRAFIA", []int{0, 2}},
+		{"tecnicas", []int{2}},
+		{"avancada marcenaria", []int{0, 1}},
+		{"escultura", nil},
+	}
+	for _, test := range tests {
+		results, err := dao.Search(ctx, test.query, 0, 0)
+		var got []int
+		for i, course := range courses {
+			for _, result := range results {
+				if record, ok := result.Record.(model.Course); ok && record.ID == course.ID {
+					got = append(got, i)
+				}
+			}
+		}
+		if err != nil || !reflect.DeepEqual(got, test.want) {
+			c.errorf("course: Search(%q) found the courses %v, %v, want %v", test.query, got, err, test.want)
+		}
+		if count, err := dao.CountSearch(ctx, test.query); err != nil || count != len(test.want) {
+			c.errorf("course: CountSearch(%q) returned %d, %v, want %d", test.query, count, err, len(test.want))
+		}
+	}
+	return c.err()
+}
+
+// run creates the fixtures and checks every operation over them
+func run[T any](ctx context.Context, name string, fixtures []T, dao crud[T]) error {
+	c := &checker{}
+	defer func() {
+		for i := range fixtures {
+			dao.delete(ctx, &fixtures[i])
+		}
+	}()
+
+	// Invalid and unknown IDs
+	if _, err := dao.get(ctx, "invalid"); err == nil {
+		c.errorf("%s: Get with an invalid ID returned no error", name)
+	}
+	if _, err := dao.get(ctx, objectid.New().Hex()); err != mongo.ErrNoDocuments {
+		c.errorf("%s: Get with an unknown ID returned %v, want mongo.ErrNoDocuments", name, err)
+	}
+
+	// Create generates a distinct ID for each document
+	before, err := dao.count(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: Count: %s", name, err)
+	}
+	ids := map[objectid.ObjectID]bool{}
+	for i := range fixtures {
+		if err := dao.create(ctx, &fixtures[i]); err != nil {
+			return fmt.Errorf("%s: Create: %s", name, err)
+		}
+		id := dao.id(fixtures[i])
+		if id == objectid.NilObjectID || ids[id] {
+			c.errorf("%s: Create generated the ID %s, want a new one", name, id.Hex())
+		}
+		ids[id] = true
+	}
+	if count, err := dao.count(ctx); err != nil || count != before+len(fixtures) {
+		c.errorf("%s: Count after Create returned %d, %v, want %d", name, count, err, before+len(fixtures))
+	}
+
+	// Get returns the created document
+	got, err := dao.get(ctx, dao.id(fixtures[0]).Hex())
+	if err != nil {
+		c.errorf("%s: Get after Create: %s", name, err)
+	} else if !reflect.DeepEqual(*got, fixtures[0]) {
+		c.errorf("%s: Get after Create returned %+v, want %+v", name, *got, fixtures[0])
+	}
+
+	// All pages in insertion order, the created documents come after the existing ones
+	page, err := dao.all(ctx, before, 2)
+	if err != nil || len(page) != 2 || dao.id(page[0]) != dao.id(fixtures[0]) || dao.id(page[1]) != dao.id(fixtures[1]) {
+		c.errorf("%s: All(%d, 2) returned %d documents, %v, want the first 2 created", name, before, len(page), err)
+	}
+	page, err = dao.all(ctx, before+2, 2)
+	if err != nil || len(page) != 1 || dao.id(page[0]) != dao.id(fixtures[2]) {
+		c.errorf("%s: All(%d, 2) returned %d documents, %v, want the last created", name, before+2, len(page), err)
+	}
+	if page, err = dao.all(ctx, before+len(fixtures), 2); err != nil || len(page) != 0 {
+		c.errorf("%s: All past the end returned %d documents, %v, want none", name, len(page), err)
+	}
+	if page, err = dao.all(ctx, before, 0); err != nil || len(page) != len(fixtures) {
+		c.errorf("%s: All without limit returned %d documents, %v, want %d", name, len(page), err, len(fixtures))
+	}
+	if _, err = dao.all(ctx, -1, 2); err == nil {
+		c.errorf("%s: All with a negative offset returned no error", name)
+	}
+
+	// Update replaces every field but the ID
+	changed := dao.change(fixtures[1])
+	if err := dao.update(ctx, &changed); err != nil {
+		c.errorf("%s: Update: %s", name, err)
+	} else if got, err := dao.get(ctx, dao.id(changed).Hex()); err != nil || !reflect.DeepEqual(*got, changed) {
+		c.errorf("%s: Get after Update returned %+v, %v, want %+v", name, got, err, changed)
+	}
+	fixtures[1] = changed
+
+	// Update and Delete of unknown documents are ignored
+	unknown := fixtures[2]
+	dao.setID(&unknown, objectid.New())
+	if err := dao.update(ctx, &unknown); err != nil {
+		c.errorf("%s: Update of an unknown document returned %s, want nil", name, err)
+	}
+	if err := dao.delete(ctx, &unknown); err != nil {
+		c.errorf("%s: Delete of an unknown document returned %s, want nil", name, err)
+	}
+	if count, err := dao.count(ctx); err != nil || count != before+len(fixtures) {
+		c.errorf("%s: Count after changing unknown documents returned %d, %v, want %d", name, count, err, before+len(fixtures))
+	}
+
+	// Delete removes the document
+	if err := dao.delete(ctx, &fixtures[2]); err != nil {
+		c.errorf("%s: Delete: %s", name, err)
+	}
+	if _, err := dao.get(ctx, dao.id(fixtures[2]).Hex()); err != mongo.ErrNoDocuments {
+		c.errorf("%s: Get after Delete returned %v, want mongo.ErrNoDocuments", name, err)
+	}
+	if count, err := dao.count(ctx); err != nil || count != before+len(fixtures)-1 {
+		c.errorf("%s: Count after Delete returned %d, %v, want %d", name, count, err, before+len(fixtures)-1)
+	}
+
+	return c.err()
+}
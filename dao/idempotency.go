@@ -0,0 +1,93 @@
+package dao
+
+import (
+	"context"
+
+	"github.com/lucasfloriani/go-mongo/app"
+	mongodb "github.com/lucasfloriani/go-mongo/db"
+	"github.com/lucasfloriani/go-mongo/model"
+	"github.com/mongodb/mongo-go-driver/bson"
+	"github.com/mongodb/mongo-go-driver/mongo"
+)
+
+// IdempotencyDAO persists the responses of requests sent with an Idempotency-Key.
+type IdempotencyDAO struct {
+	db *mongo.Collection
+}
+
+// NewIdempotencyDAO creates a new IdempotencyDAO
+func NewIdempotencyDAO(db *mongo.Database) *IdempotencyDAO {
+	return &IdempotencyDAO{db.Collection("idempotency", mongodb.CollectionOptions("idempotency")...)}
+}
+
+func (dao *IdempotencyDAO) collection() *mongo.Collection {
+	return dao.db
+}
+
+// Indexes returns the indexes of the idempotency collection, the records expire after app.Config.Idempotency.TTL.
+func (dao *IdempotencyDAO) Indexes() []Index {
+	return []Index{
+		{
+			Name:        "created_at_ttl",
+			Keys:        bson.NewDocument(bson.EC.Int32("created_at", 1)),
+			ExpireAfter: app.Config.Idempotency.TTL,
+		},
+	}
+}
+
+// Reserve saves the record when there isn't one with the same key yet and returns nil,
+// else returns the existing record without changing it.
+func (dao *IdempotencyDAO) Reserve(ctx context.Context, r *model.IdempotencyRecord) (*model.IdempotencyRecord, error) {
+	_, err := dao.db.InsertOne(
+		ctx,
+		bson.NewDocument(
+			bson.EC.String("_id", r.Key),
+			bson.EC.String("fingerprint", r.Fingerprint),
+			bson.EC.Time("created_at", r.CreatedAt),
+		),
+	)
+	if err == nil {
+		return nil, nil
+	}
+	if !mongodb.IsDuplicateKey(err) {
+		return nil, err
+	}
+
+	existing := &model.IdempotencyRecord{}
+	err = dao.db.FindOne(
+		ctx,
+		bson.NewDocument(
+			bson.EC.String("_id", r.Key),
+		),
+	).Decode(existing)
+	return existing, err
+}
+
+// Complete saves the response of the record.
+func (dao *IdempotencyDAO) Complete(ctx context.Context, r *model.IdempotencyRecord) error {
+	_, err := dao.db.UpdateOne(
+		ctx,
+		bson.NewDocument(
+			bson.EC.String("_id", r.Key),
+		),
+		bson.NewDocument(
+			bson.EC.SubDocumentFromElements("$set",
+				bson.EC.Int32("status", int32(r.Status)),
+				bson.EC.String("content_type", r.ContentType),
+				bson.EC.Binary("body", r.Body),
+			),
+		),
+	)
+	return err
+}
+
+// Delete deletes the record so the key can be used again.
+func (dao *IdempotencyDAO) Delete(ctx context.Context, r *model.IdempotencyRecord) error {
+	_, err := dao.db.DeleteOne(
+		ctx,
+		bson.NewDocument(
+			bson.EC.String("_id", r.Key),
+		),
+	)
+	return err
+}
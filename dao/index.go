@@ -0,0 +1,240 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lucasfloriani/go-mongo/app"
+	"github.com/mongodb/mongo-go-driver/bson"
+	"github.com/mongodb/mongo-go-driver/mongo"
+)
+
+// defaultIndex is the index created by Mongo in every collection
+const defaultIndex = "_id_"
+
+// Index declares an index of a collection. Text indexes use "text" as the value of their keys.
+type Index struct {
+	Name string
+	Keys *bson.Document
+	// Unique rejects documents with the same keys
+	Unique bool
+	// PartialFilter limits the index to the documents matching it
+	PartialFilter *bson.Document
+	// ExpireAfter removes the documents older than it, the index must have a single date key
+	ExpireAfter time.Duration
+	// DefaultLanguage is the language used by text indexes
+	DefaultLanguage string
+	// Weights are the weights of the text index keys
+	Weights *bson.Document
+}
+
+// model returns the index model used to create the index
+func (i Index) model() mongo.IndexModel {
+	opts := mongo.NewIndexOptionsBuilder().Name(i.Name)
+	if i.Unique {
+		opts = opts.Unique(true)
+	}
+	if i.PartialFilter != nil {
+		opts = opts.PartialFilterExpression(i.PartialFilter)
+	}
+	if i.ExpireAfter > 0 {
+		opts = opts.ExpireAfterSeconds(int32(i.ExpireAfter.Seconds()))
+	}
+	if i.DefaultLanguage != "" {
+		opts = opts.DefaultLanguage(i.DefaultLanguage)
+	}
+	if i.Weights != nil {
+		opts = opts.Weights(i.Weights)
+	}
+	return mongo.IndexModel{Keys: i.Keys, Options: opts.Build()}
+}
+
+// indexer is implemented by the DAOs that declare the indexes of their collection.
+type indexer interface {
+	collection() *mongo.Collection
+	Indexes() []Index
+}
+
+// IndexDiff is the difference between the declared and the existing indexes of a collection.
+type IndexDiff struct {
+	Collection string
+	// Missing are the declared indexes that don't exist
+	Missing []string
+	// Unexpected are the existing indexes that aren't declared
+	Unexpected []string
+	// Changed are the indexes that exist with another definition, they are dropped and created again
+	Changed []string
+}
+
+// Empty check if the existing indexes are the declared ones
+func (d IndexDiff) Empty() bool {
+	return len(d.Missing) == 0 && len(d.Unexpected) == 0 && len(d.Changed) == 0
+}
+
+func (d IndexDiff) String() string {
+	var lines []string
+	for _, name := range d.Missing {
+		lines = append(lines, fmt.Sprintf("+ %s.%s", d.Collection, name))
+	}
+	for _, name := range d.Changed {
+		lines = append(lines, fmt.Sprintf("-+ %s.%s (drop and create again)", d.Collection, name))
+	}
+	for _, name := range d.Unexpected {
+		lines = append(lines, fmt.Sprintf("- %s.%s", d.Collection, name))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// indexers returns the DAOs with declared indexes
+func indexers(db *mongo.Database) []indexer {
+	return []indexer{
+		NewUserDAO(db),
+		NewCourseDAO(db),
+		NewAPIKeyDAO(db),
+		NewIdempotencyDAO(db),
+	}
+}
+
+// DiffIndexes compares the indexes declared by the DAOs with the existing ones, without changing them.
+func DiffIndexes(ctx context.Context, db *mongo.Database) ([]IndexDiff, error) {
+	var diffs []IndexDiff
+	for _, dao := range indexers(db) {
+		diff, err := diffIndexes(ctx, dao)
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs, nil
+}
+
+// SyncIndexes creates the declared indexes that are missing and drops and creates again the ones
+// with another definition. The unexpected indexes are only reported, unless
+// app.Config.Database.Indexes.DropUnexpected is set, then they are dropped.
+func SyncIndexes(ctx context.Context, db *mongo.Database) ([]IndexDiff, error) {
+	drop := app.Config.Database.Indexes.DropUnexpected
+	logger := app.Logger("dao")
+
+	diffs, err := DiffIndexes(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	for i, dao := range indexers(db) {
+		diff := diffs[i]
+		declared := map[string]Index{}
+		for _, index := range dao.Indexes() {
+			declared[index.Name] = index
+		}
+		indexes := dao.collection().Indexes()
+
+		dropped := diff.Changed
+		if drop {
+			dropped = append(dropped, diff.Unexpected...)
+		} else if len(diff.Unexpected) > 0 {
+			logger.Warn("indexes not declared", "collection", diff.Collection,
+				"unexpected", strings.Join(diff.Unexpected, ", "))
+		}
+		for _, name := range dropped {
+			if _, err := indexes.DropOne(ctx, name); err != nil {
+				return nil, fmt.Errorf("Failed to drop the index %s.%s: %s", diff.Collection, name, err)
+			}
+			logger.Info("index dropped", "collection", diff.Collection, "index", name)
+		}
+
+		for _, name := range append(diff.Missing, diff.Changed...) {
+			if _, err := indexes.CreateOne(ctx, declared[name].model()); err != nil {
+				return nil, fmt.Errorf("Failed to create the index %s.%s: %s", diff.Collection, name, err)
+			}
+			logger.Info("index created", "collection", diff.Collection, "index", name)
+		}
+	}
+	return diffs, nil
+}
+
+// diffIndexes compares the indexes declared by the DAO with the existing ones
+func diffIndexes(ctx context.Context, dao indexer) (IndexDiff, error) {
+	coll := dao.collection()
+	diff := IndexDiff{Collection: coll.Name()}
+
+	existing := map[string]*bson.Document{}
+	cur, err := coll.Indexes().List(ctx)
+	if err != nil {
+		return diff, err
+	}
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
+		index := bson.NewDocument()
+		if err := cur.Decode(index); err != nil {
+			return diff, err
+		}
+		name := index.Lookup("name").StringValue()
+		if name != defaultIndex {
+			existing[name] = index
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return diff, err
+	}
+
+	declared := map[string]bool{}
+	for _, index := range dao.Indexes() {
+		declared[index.Name] = true
+		spec, ok := existing[index.Name]
+		switch {
+		case !ok:
+			diff.Missing = append(diff.Missing, index.Name)
+		case !sameKeys(index, spec.Lookup("key").MutableDocument()) || !sameOptions(index, spec):
+			diff.Changed = append(diff.Changed, index.Name)
+		}
+	}
+	for name := range existing {
+		if !declared[name] {
+			diff.Unexpected = append(diff.Unexpected, name)
+		}
+	}
+	return diff, nil
+}
+
+// sameKeys check if the existing keys are the declared ones. Mongo stores the keys of the text
+// indexes as _fts and _ftsx, so those are only compared by name.
+func sameKeys(index Index, keys *bson.Document) bool {
+	if _, err := keys.LookupErr("_fts"); err == nil {
+		return true
+	}
+	return index.Keys.Equal(keys)
+}
+
+// sameOptions check if the existing index has the declared unique, partial filter and expiration
+// options. The options of the text indexes are compared by sameKeys with their keys.
+func sameOptions(index Index, spec *bson.Document) bool {
+	unique := false
+	if value, err := spec.LookupErr("unique"); err == nil {
+		unique = value.Type() == bson.TypeBoolean && value.Boolean()
+	}
+	if unique != index.Unique {
+		return false
+	}
+
+	filter, err := spec.LookupErr("partialFilterExpression")
+	switch {
+	case err != nil && index.PartialFilter != nil:
+		return false
+	case err == nil && (index.PartialFilter == nil || !index.PartialFilter.Equal(filter.MutableDocument())):
+		return false
+	}
+
+	var expire int64
+	if value, err := spec.LookupErr("expireAfterSeconds"); err == nil {
+		switch value.Type() {
+		case bson.TypeInt32:
+			expire = int64(value.Int32())
+		case bson.TypeInt64:
+			expire = value.Int64()
+		case bson.TypeDouble:
+			expire = int64(value.Double())
+		}
+	}
+	return expire == int64(index.ExpireAfter.Seconds())
+}
@@ -0,0 +1,236 @@
+package dao
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	mongodb "github.com/lucasfloriani/go-mongo/db"
+	"github.com/lucasfloriani/go-mongo/model"
+	"github.com/lucasfloriani/go-mongo/search"
+
+	"github.com/mongodb/mongo-go-driver/bson/objectid"
+	"github.com/mongodb/mongo-go-driver/mongo"
+)
+
+// errNegativeSkip is returned, like the server does, when the offset is negative
+var errNegativeSkip = errors.New("skip value must be non-negative")
+
+// memoryCollection is a thread-safe collection of documents kept in memory, in insertion order.
+// The documents are copied on the way in and out, so callers can't change the stored ones.
+type memoryCollection[T any] struct {
+	mu    sync.RWMutex
+	ids   []objectid.ObjectID
+	items map[objectid.ObjectID]T
+	clone func(T) T
+	setID func(*T, objectid.ObjectID)
+}
+
+// newMemoryCollection creates an empty collection, copying the documents with clone.
+// setID stores the generated ID in a new document, like the _id field of Mongo.
+func newMemoryCollection[T any](clone func(T) T, setID func(*T, objectid.ObjectID)) *memoryCollection[T] {
+	return &memoryCollection[T]{items: map[objectid.ObjectID]T{}, clone: clone, setID: setID}
+}
+
+// all returns the documents with the specified offset and limit, a zero limit returns every
+// document after the offset and a negative one is used as positive, like in Mongo
+func (c *memoryCollection[T]) all(offset, limit int) ([]T, error) {
+	return c.filter(nil, offset, limit)
+}
+
+// filter returns the documents accepted by match, or every one when it's nil,
+// with the specified offset and limit like all
+func (c *memoryCollection[T]) filter(match func(T) bool, offset, limit int) ([]T, error) {
+	if offset < 0 {
+		return nil, errNegativeSkip
+	}
+	if limit < 0 {
+		limit = -limit
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var elements []T
+	for _, id := range c.ids {
+		if limit != 0 && len(elements) == limit {
+			break
+		}
+		if item := c.items[id]; match == nil || match(item) {
+			if offset > 0 {
+				offset--
+				continue
+			}
+			elements = append(elements, c.clone(item))
+		}
+	}
+	return elements, nil
+}
+
+// search returns the documents whose text matches the full-text query, sorted by the relevance
+// given by search.Score, with the specified offset and limit like all
+func (c *memoryCollection[T]) search(collection, query string, text func(T) string, offset, limit int) ([]model.SearchResult, error) {
+	if offset < 0 {
+		return nil, errNegativeSkip
+	}
+	if limit < 0 {
+		limit = -limit
+	}
+	results := c.matching(collection, query, text)
+	if offset > len(results) {
+		offset = len(results)
+	}
+	results = results[offset:]
+	if limit > 0 && limit < len(results) {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// countSearch returns the number of documents whose text matches the full-text query
+func (c *memoryCollection[T]) countSearch(query string, text func(T) string) int {
+	return len(c.matching("", query, text))
+}
+
+// matching returns every document whose text matches the full-text query, by relevance
+func (c *memoryCollection[T]) matching(collection, query string, text func(T) string) []model.SearchResult {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var results []model.SearchResult
+	for _, id := range c.ids {
+		item := c.items[id]
+		if score := search.Score(text(item), query); score > 0 {
+			results = append(results, model.SearchResult{Type: collection, Score: score, Record: c.clone(item)})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	return results
+}
+
+// suggest returns the ID and the name of the documents whose normalized name starts with the
+// normalized prefix, by normalized name up to the limit, like the Mongo DAOs
+func (c *memoryCollection[T]) suggest(prefix string, name func(T) string, limit int) []model.Suggestion {
+	prefix = search.Normalize(prefix)
+	c.mu.RLock()
+	var suggestions []model.Suggestion
+	for _, id := range c.ids {
+		if n := name(c.items[id]); strings.HasPrefix(search.Normalize(n), prefix) {
+			suggestions = append(suggestions, model.Suggestion{ID: id, Name: n})
+		}
+	}
+	c.mu.RUnlock()
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return search.Normalize(suggestions[i].Name) < search.Normalize(suggestions[j].Name)
+	})
+	if limit > 0 && limit < len(suggestions) {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
+// count returns the number of documents
+func (c *memoryCollection[T]) count() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.ids)
+}
+
+// countFilter returns the number of documents accepted by match
+func (c *memoryCollection[T]) countFilter(match func(T) bool) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	count := 0
+	for _, item := range c.items {
+		if match(item) {
+			count++
+		}
+	}
+	return count
+}
+
+// get returns the document with the specified hex ID, failing with the same errors
+// of the Mongo DAOs for invalid and unknown IDs
+func (c *memoryCollection[T]) get(id string) (T, error) {
+	var zero T
+	objID, err := objectid.FromHex(id)
+	if err != nil {
+		return zero, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	item, ok := c.items[objID]
+	if !ok {
+		return zero, mongo.ErrNoDocuments
+	}
+	return c.clone(item), nil
+}
+
+// find returns the first document that matches
+func (c *memoryCollection[T]) find(match func(T) bool) (T, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, id := range c.ids {
+		if item := c.items[id]; match(item) {
+			return c.clone(item), nil
+		}
+	}
+	var zero T
+	return zero, mongo.ErrNoDocuments
+}
+
+// insert saves a new document, generating its ID and storing it in the document. The document is rejected with the duplicate
+// key error of Mongo when unique reports another document with the same unique fields.
+func (c *memoryCollection[T]) insert(item T, unique func(a, b T) bool) (objectid.ObjectID, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if unique != nil {
+		for _, existing := range c.items {
+			if unique(existing, item) {
+				return objectid.NilObjectID, mongo.WriteErrors{{Code: mongodb.DuplicateKeyCode, Message: "E11000 duplicate key error"}}
+			}
+		}
+	}
+	id := objectid.New()
+	c.setID(&item, id)
+	c.ids = append(c.ids, id)
+	c.items[id] = c.clone(item)
+	return id, nil
+}
+
+// update changes the document with the specified ID, ignoring unknown IDs like UpdateOne.
+// The change is rejected like in insert when unique reports another document with the same unique fields.
+func (c *memoryCollection[T]) update(id objectid.ObjectID, change func(*T), unique func(a, b T) bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	item, ok := c.items[id]
+	if !ok {
+		return nil
+	}
+	change(&item)
+	if unique != nil {
+		for existingID, existing := range c.items {
+			if existingID != id && unique(existing, item) {
+				return mongo.WriteErrors{{Code: mongodb.DuplicateKeyCode, Message: "E11000 duplicate key error"}}
+			}
+		}
+	}
+	c.items[id] = c.clone(item)
+	return nil
+}
+
+// delete deletes the document with the specified ID, ignoring unknown IDs like DeleteOne
+func (c *memoryCollection[T]) delete(id objectid.ObjectID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.items[id]; !ok {
+		return
+	}
+	delete(c.items, id)
+	for i := range c.ids {
+		if c.ids[i] == id {
+			c.ids = append(c.ids[:i], c.ids[i+1:]...)
+			break
+		}
+	}
+}
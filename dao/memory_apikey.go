@@ -0,0 +1,92 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/lucasfloriani/go-mongo/model"
+
+	"github.com/mongodb/mongo-go-driver/bson/objectid"
+)
+
+// MemoryAPIKeyDAO keeps the API keys in memory, behaving like APIKeyDAO.
+// It's used by the "memory" storage, for demos and tests without Mongo.
+type MemoryAPIKeyDAO struct {
+	keys *memoryCollection[model.APIKey]
+}
+
+// NewMemoryAPIKeyDAO creates a new, empty, MemoryAPIKeyDAO
+func NewMemoryAPIKeyDAO() *MemoryAPIKeyDAO {
+	return &MemoryAPIKeyDAO{newMemoryCollection(copyAPIKey, func(k *model.APIKey, id objectid.ObjectID) { k.ID = id })}
+}
+
+// All retrieves the API keys with the specified offset and limit.
+func (dao *MemoryAPIKeyDAO) All(ctx context.Context, offset, limit int) ([]model.APIKey, error) {
+	return dao.keys.all(offset, limit)
+}
+
+// Count returns the number of API keys.
+func (dao *MemoryAPIKeyDAO) Count(ctx context.Context) (int, error) {
+	return dao.keys.count(), nil
+}
+
+// Get reads the API key with the specified ID.
+func (dao *MemoryAPIKeyDAO) Get(ctx context.Context, id string) (*model.APIKey, error) {
+	k, err := dao.keys.get(id)
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// GetByHash reads the API key with the specified hash.
+func (dao *MemoryAPIKeyDAO) GetByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	k, err := dao.keys.find(func(k model.APIKey) bool { return k.Hash == hash })
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// Create saves a new API key, the hash is unique like in the hash_1 index.
+// The APIKey.Id field will be populated with an automatically generated ID upon successful saving.
+func (dao *MemoryAPIKeyDAO) Create(ctx context.Context, k *model.APIKey) error {
+	stored := *k
+	stored.Key = ""
+	id, err := dao.keys.insert(stored, sameHash)
+	if err != nil {
+		return err
+	}
+	k.ID = id
+	return nil
+}
+
+// Update saves the changes to an API key, except its owner and usage.
+func (dao *MemoryAPIKeyDAO) Update(ctx context.Context, k *model.APIKey) error {
+	return dao.keys.update(k.ID, func(stored *model.APIKey) {
+		stored.Name = k.Name
+		stored.Prefix = k.Prefix
+		stored.Hash = k.Hash
+		stored.Scopes = k.Scopes
+		stored.ExpiresAt = k.ExpiresAt
+		stored.Revoked = k.Revoked
+	}, sameHash)
+}
+
+// Touch saves the last time the API key with the specified ID was used.
+func (dao *MemoryAPIKeyDAO) Touch(ctx context.Context, k *model.APIKey, usedAt time.Time) error {
+	return dao.keys.update(k.ID, func(stored *model.APIKey) {
+		stored.LastUsedAt = usedAt
+	}, nil)
+}
+
+// sameHash check if the API keys have the same hash, unique like in the hash_1 index
+func sameHash(a, b model.APIKey) bool {
+	return a.Hash == b.Hash
+}
+
+// copyAPIKey copies the API key with its scopes
+func copyAPIKey(k model.APIKey) model.APIKey {
+	k.Scopes = append([]string(nil), k.Scopes...)
+	return k
+}
@@ -0,0 +1,84 @@
+package dao
+
+import (
+	"context"
+
+	"github.com/lucasfloriani/go-mongo/model"
+
+	"github.com/mongodb/mongo-go-driver/bson/objectid"
+)
+
+// MemoryCourseDAO keeps the courses in memory, behaving like CourseDAO.
+// It's used by the "memory" storage, for demos and tests without Mongo.
+type MemoryCourseDAO struct {
+	courses *memoryCollection[model.Course]
+}
+
+// NewMemoryCourseDAO creates a new, empty, MemoryCourseDAO
+func NewMemoryCourseDAO() *MemoryCourseDAO {
+	return &MemoryCourseDAO{newMemoryCollection(func(c model.Course) model.Course { return c }, func(c *model.Course, id objectid.ObjectID) { c.ID = id })}
+}
+
+// All retrieves the courses with the specified offset and limit.
+func (dao *MemoryCourseDAO) All(ctx context.Context, offset, limit int) ([]model.Course, error) {
+	return dao.courses.all(offset, limit)
+}
+
+// Count returns the number of courses.
+func (dao *MemoryCourseDAO) Count(ctx context.Context) (int, error) {
+	return dao.courses.count(), nil
+}
+
+// Search retrieves the courses matching the full-text query, by relevance, with the specified offset and limit.
+func (dao *MemoryCourseDAO) Search(ctx context.Context, query string, offset, limit int) ([]model.SearchResult, error) {
+	return dao.courses.search("course", query, courseName, offset, limit)
+}
+
+// CountSearch returns the number of the courses matching the full-text query.
+func (dao *MemoryCourseDAO) CountSearch(ctx context.Context, query string) (int, error) {
+	return dao.courses.countSearch(query, courseName), nil
+}
+
+// Suggest retrieves the courses whose name starts with the prefix, ignoring case and diacritics,
+// by name up to the limit.
+func (dao *MemoryCourseDAO) Suggest(ctx context.Context, prefix string, limit int) ([]model.Suggestion, error) {
+	return dao.courses.suggest(prefix, courseName, limit), nil
+}
+
+// Get reads the course with the specified ID.
+func (dao *MemoryCourseDAO) Get(ctx context.Context, id string) (*model.Course, error) {
+	c, err := dao.courses.get(id)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Create saves a new course.
+// The Course.Id field will be populated with an automatically generated ID upon successful saving.
+func (dao *MemoryCourseDAO) Create(ctx context.Context, c *model.Course) error {
+	id, err := dao.courses.insert(*c, nil)
+	if err != nil {
+		return err
+	}
+	c.ID = id
+	return nil
+}
+
+// Update saves the changes to a course.
+func (dao *MemoryCourseDAO) Update(ctx context.Context, c *model.Course) error {
+	return dao.courses.update(c.ID, func(stored *model.Course) {
+		*stored = *c
+	}, nil)
+}
+
+// Delete deletes a course with the specified ID.
+func (dao *MemoryCourseDAO) Delete(ctx context.Context, c *model.Course) error {
+	dao.courses.delete(c.ID)
+	return nil
+}
+
+// courseName returns the name of the course, matched by the full-text search and the suggestions
+func courseName(c model.Course) string {
+	return c.Name
+}
@@ -0,0 +1,83 @@
+package dao
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lucasfloriani/go-mongo/app"
+	"github.com/lucasfloriani/go-mongo/model"
+)
+
+// MemoryIdempotencyDAO keeps the responses of requests sent with an Idempotency-Key in memory,
+// behaving like IdempotencyDAO. The records expire after app.Config.Idempotency.TTL, replaced
+// when their key is reserved again and swept at most once per idempotencySweepInterval.
+type MemoryIdempotencyDAO struct {
+	mu        sync.Mutex
+	records   map[string]model.IdempotencyRecord
+	lastSweep time.Time
+}
+
+// idempotencySweepInterval is the interval between removals of the expired records
+const idempotencySweepInterval = time.Minute
+
+// NewMemoryIdempotencyDAO creates a new, empty, MemoryIdempotencyDAO
+func NewMemoryIdempotencyDAO() *MemoryIdempotencyDAO {
+	return &MemoryIdempotencyDAO{records: map[string]model.IdempotencyRecord{}}
+}
+
+// Reserve saves the record when there isn't one with the same key yet and returns nil,
+// else returns the existing record without changing it.
+func (dao *MemoryIdempotencyDAO) Reserve(ctx context.Context, r *model.IdempotencyRecord) (*model.IdempotencyRecord, error) {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+	now := time.Now()
+	if now.Sub(dao.lastSweep) > idempotencySweepInterval {
+		dao.sweep(now)
+	}
+	if existing, ok := dao.records[r.Key]; ok && !dao.expired(existing, now) {
+		return &existing, nil
+	}
+	dao.records[r.Key] = model.IdempotencyRecord{
+		Key:         r.Key,
+		Fingerprint: r.Fingerprint,
+		CreatedAt:   r.CreatedAt,
+	}
+	return nil, nil
+}
+
+// Complete saves the response of the record.
+func (dao *MemoryIdempotencyDAO) Complete(ctx context.Context, r *model.IdempotencyRecord) error {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+	if stored, ok := dao.records[r.Key]; ok {
+		stored.Status = r.Status
+		stored.ContentType = r.ContentType
+		stored.Body = append([]byte(nil), r.Body...)
+		dao.records[r.Key] = stored
+	}
+	return nil
+}
+
+// Delete deletes the record so the key can be used again.
+func (dao *MemoryIdempotencyDAO) Delete(ctx context.Context, r *model.IdempotencyRecord) error {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+	delete(dao.records, r.Key)
+	return nil
+}
+
+// sweep removes the expired records, the ones whose key is never used again
+func (dao *MemoryIdempotencyDAO) sweep(now time.Time) {
+	dao.lastSweep = now
+	for key, record := range dao.records {
+		if dao.expired(record, now) {
+			delete(dao.records, key)
+		}
+	}
+}
+
+// expired check if the record is older than app.Config.Idempotency.TTL
+func (dao *MemoryIdempotencyDAO) expired(record model.IdempotencyRecord, now time.Time) bool {
+	return now.Sub(record.CreatedAt) >= app.Config.Idempotency.TTL
+}
@@ -0,0 +1,177 @@
+package dao
+
+import (
+	"context"
+	"sort"
+
+	"github.com/lucasfloriani/go-mongo/model"
+	"github.com/mongodb/mongo-go-driver/bson/objectid"
+)
+
+// MemoryReportDAO aggregates the users of a MemoryUserDAO, behaving like ReportDAO.
+type MemoryReportDAO struct {
+	users *MemoryUserDAO
+}
+
+// NewMemoryReportDAO creates a new MemoryReportDAO over the users of the DAO
+func NewMemoryReportDAO(users *MemoryUserDAO) *MemoryReportDAO {
+	return &MemoryReportDAO{users}
+}
+
+// Enrollments returns the number of users enrolled in each course, most enrolled first.
+func (dao *MemoryReportDAO) Enrollments(ctx context.Context, filter model.ReportFilter) (model.EnrollmentReport, error) {
+	users, err := dao.match(filter, nil)
+	if err != nil {
+		return nil, err
+	}
+	report := model.EnrollmentReport{}
+	index := map[objectid.ObjectID]int{}
+	for _, u := range users {
+		for _, c := range u.Courses {
+			i, ok := index[c.ID]
+			if !ok {
+				i = len(report)
+				index[c.ID] = i
+				report = append(report, model.Enrollment{CourseID: c.ID, Course: c.Name})
+			}
+			report[i].Users++
+		}
+	}
+	sort.SliceStable(report, func(i, j int) bool {
+		if report[i].Users != report[j].Users {
+			return report[i].Users > report[j].Users
+		}
+		return report[i].Course < report[j].Course
+	})
+	return report, nil
+}
+
+// Ages returns the number of users in each bucket between the boundaries, which must be
+// sorted. Users younger than the first boundary or as old as the last one aren't counted.
+func (dao *MemoryReportDAO) Ages(ctx context.Context, filter model.ReportFilter, boundaries []uint) (model.AgeReport, error) {
+	users, err := dao.match(filter, boundaries)
+	if err != nil {
+		return nil, err
+	}
+	report := newAgeReport(boundaries)
+	for _, u := range users {
+		for i := range report {
+			if u.Age >= report[i].Min && u.Age < report[i].Max {
+				report[i].Users++
+			}
+		}
+	}
+	return report, nil
+}
+
+// Phones returns the statistics of the number of phones of the users.
+func (dao *MemoryReportDAO) Phones(ctx context.Context, filter model.ReportFilter) (*model.PhoneReport, error) {
+	users, err := dao.match(filter, nil)
+	if err != nil {
+		return nil, err
+	}
+	report := &model.PhoneReport{Distribution: []model.PhoneCount{}}
+	counts := map[int]int{}
+	for i, u := range users {
+		phones := len(u.Phones)
+		if i == 0 || phones < report.Min {
+			report.Min = phones
+		}
+		if phones > report.Max {
+			report.Max = phones
+		}
+		report.Users++
+		report.Phones += phones
+		counts[phones]++
+	}
+	if report.Users > 0 {
+		report.Average = float64(report.Phones) / float64(report.Users)
+	}
+	for phones, users := range counts {
+		report.Distribution = append(report.Distribution, model.PhoneCount{Phones: phones, Users: users})
+	}
+	sort.Slice(report.Distribution, func(i, j int) bool { return report.Distribution[i].Phones < report.Distribution[j].Phones })
+	return report, nil
+}
+
+// Addresses returns the number of users grouped by the address field (see model.AddressReportFields),
+// most users first. Users without the field are grouped in an empty value.
+func (dao *MemoryReportDAO) Addresses(ctx context.Context, filter model.ReportFilter, field string) (model.AddressReport, error) {
+	users, err := dao.match(filter, nil)
+	if err != nil {
+		return nil, err
+	}
+	report := model.AddressReport{}
+	index := map[string]int{}
+	for _, u := range users {
+		value := addressField(u.Address, field)
+		i, ok := index[value]
+		if !ok {
+			i = len(report)
+			index[value] = i
+			report = append(report, model.AddressGroup{Value: value})
+		}
+		report[i].Users++
+	}
+	sort.SliceStable(report, func(i, j int) bool {
+		if report[i].Users != report[j].Users {
+			return report[i].Users > report[j].Users
+		}
+		return report[i].Value < report[j].Value
+	})
+	return report, nil
+}
+
+// match returns the users selected by the filter, limited to the ages between the first
+// and the last boundaries when given
+func (dao *MemoryReportDAO) match(filter model.ReportFilter, boundaries []uint) ([]model.User, error) {
+	var courseID objectid.ObjectID
+	if filter.CourseID != "" {
+		var err error
+		if courseID, err = objectid.FromHex(filter.CourseID); err != nil {
+			return nil, err
+		}
+	}
+	minAge, maxAge := ageRange(filter, boundaries)
+
+	all, err := dao.users.All(context.Background(), 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	var users []model.User
+	for _, u := range all {
+		if minAge > 0 && u.Age < minAge || maxAge > 0 && u.Age > maxAge {
+			continue
+		}
+		if filter.CourseID != "" && !enrolled(u, courseID) {
+			continue
+		}
+		users = append(users, u)
+	}
+	return users, nil
+}
+
+// enrolled check if the user is enrolled in the course
+func enrolled(u model.User, courseID objectid.ObjectID) bool {
+	for _, c := range u.Courses {
+		if c.ID == courseID {
+			return true
+		}
+	}
+	return false
+}
+
+// addressField returns the value of the address field grouped by the reports
+func addressField(a model.Address, field string) string {
+	switch field {
+	case "state":
+		return a.State
+	case "city":
+		return a.City
+	case "neighborhood":
+		return a.Neighborhood
+	case "country":
+		return a.Country
+	}
+	return ""
+}
@@ -0,0 +1,21 @@
+package dao_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lucasfloriani/go-mongo/dao"
+	"github.com/lucasfloriani/go-mongo/dao/daotest"
+)
+
+func TestMemoryUserDAO(t *testing.T) {
+	if err := daotest.TestUserDAO(context.Background(), dao.NewMemoryUserDAO()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMemoryCourseDAO(t *testing.T) {
+	if err := daotest.TestCourseDAO(context.Background(), dao.NewMemoryCourseDAO()); err != nil {
+		t.Error(err)
+	}
+}
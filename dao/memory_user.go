@@ -0,0 +1,140 @@
+package dao
+
+import (
+	"context"
+
+	"github.com/lucasfloriani/go-mongo/model"
+
+	"github.com/mongodb/mongo-go-driver/bson/objectid"
+)
+
+// MemoryUserDAO keeps the users in memory, behaving like UserDAO.
+// It's used by the "memory" storage, for demos and tests without Mongo.
+type MemoryUserDAO struct {
+	users *memoryCollection[model.User]
+}
+
+// NewMemoryUserDAO creates a new, empty, MemoryUserDAO
+func NewMemoryUserDAO() *MemoryUserDAO {
+	return &MemoryUserDAO{newMemoryCollection(copyUser, func(u *model.User, id objectid.ObjectID) { u.ID = id })}
+}
+
+// All retrieves the users with the specified offset and limit.
+func (dao *MemoryUserDAO) All(ctx context.Context, offset, limit int) ([]model.User, error) {
+	return dao.users.all(offset, limit)
+}
+
+// Count returns the number of users.
+func (dao *MemoryUserDAO) Count(ctx context.Context) (int, error) {
+	return dao.users.count(), nil
+}
+
+// ByPhone retrieves the users with the phone number, in the E.164 format, with the specified offset and limit.
+func (dao *MemoryUserDAO) ByPhone(ctx context.Context, number string, offset, limit int) ([]model.User, error) {
+	return dao.users.filter(hasPhone(number), offset, limit)
+}
+
+// CountByPhone returns the number of users with the phone number, in the E.164 format.
+func (dao *MemoryUserDAO) CountByPhone(ctx context.Context, number string) (int, error) {
+	return dao.users.countFilter(hasPhone(number)), nil
+}
+
+// Search retrieves the users matching the full-text query, by relevance, with the specified offset and limit.
+func (dao *MemoryUserDAO) Search(ctx context.Context, query string, offset, limit int) ([]model.SearchResult, error) {
+	return dao.users.search("user", query, userName, offset, limit)
+}
+
+// CountSearch returns the number of the users matching the full-text query.
+func (dao *MemoryUserDAO) CountSearch(ctx context.Context, query string) (int, error) {
+	return dao.users.countSearch(query, userName), nil
+}
+
+// Suggest retrieves the users whose name starts with the prefix, ignoring case and diacritics,
+// by name up to the limit.
+func (dao *MemoryUserDAO) Suggest(ctx context.Context, prefix string, limit int) ([]model.Suggestion, error) {
+	return dao.users.suggest(prefix, userName, limit), nil
+}
+
+// GetByCPF reads the user with the specified CPF, normalized.
+func (dao *MemoryUserDAO) GetByCPF(ctx context.Context, cpf model.CPF) (*model.User, error) {
+	u, err := dao.users.find(func(u model.User) bool { return u.CPF == cpf })
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// GetByEmail reads the user with the specified email, normalized.
+func (dao *MemoryUserDAO) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	u, err := dao.users.find(func(u model.User) bool { return u.Email == email })
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// Get reads the user with the specified ID.
+func (dao *MemoryUserDAO) Get(ctx context.Context, id string) (*model.User, error) {
+	u, err := dao.users.get(id)
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// Create saves a new user.
+// The User.Id field will be populated with an automatically generated ID upon successful saving.
+func (dao *MemoryUserDAO) Create(ctx context.Context, u *model.User) error {
+	id, err := dao.users.insert(*u, sameIdentity)
+	if err != nil {
+		return err
+	}
+	u.ID = id
+	return nil
+}
+
+// Update saves the changes to an user.
+func (dao *MemoryUserDAO) Update(ctx context.Context, u *model.User) error {
+	return dao.users.update(u.ID, func(stored *model.User) {
+		*stored = *u
+	}, sameIdentity)
+}
+
+// Delete deletes an user with the specified ID.
+func (dao *MemoryUserDAO) Delete(ctx context.Context, u *model.User) error {
+	dao.users.delete(u.ID)
+	return nil
+}
+
+// copyUser copies the user with its phones and courses
+func copyUser(u model.User) model.User {
+	if u.Phones != nil {
+		u.Phones = append(make([]model.Phone, 0, len(u.Phones)), u.Phones...)
+	}
+	if u.Courses != nil {
+		u.Courses = append(make([]model.Course, 0, len(u.Courses)), u.Courses...)
+	}
+	return u
+}
+
+// hasPhone returns a match of the users with the phone number
+func hasPhone(number string) func(model.User) bool {
+	return func(u model.User) bool {
+		for _, p := range u.Phones {
+			if p.Number == number {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// sameIdentity check if the users have the same CPF or email, unique like in the cpf_1 and email_1 indexes
+func sameIdentity(a, b model.User) bool {
+	return (a.CPF != "" && a.CPF == b.CPF) || (a.Email != "" && a.Email == b.Email)
+}
+
+// userName returns the name of the user, matched by the full-text search and the suggestions
+func userName(u model.User) string {
+	return u.Name
+}
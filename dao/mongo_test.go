@@ -0,0 +1,52 @@
+package dao_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/lucasfloriani/go-mongo/dao"
+	"github.com/lucasfloriani/go-mongo/dao/daotest"
+
+	"github.com/mongodb/mongo-go-driver/bson/objectid"
+	"github.com/mongodb/mongo-go-driver/mongo"
+)
+
+// testDatabase returns an empty database, with the declared indexes, in the Mongo of the
+// GOMONGO_E2E_MONGO environment variable. The test is skipped when it isn't set.
+func testDatabase(t *testing.T) *mongo.Database {
+	t.Helper()
+	connection := os.Getenv("GOMONGO_E2E_MONGO")
+	if connection == "" {
+		t.Skip("GOMONGO_E2E_MONGO not set")
+	}
+	ctx := context.Background()
+	client, err := mongo.NewClient(connection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Connect(ctx); err != nil {
+		t.Fatal(err)
+	}
+	database := client.Database("dao_" + objectid.New().Hex())
+	t.Cleanup(func() {
+		database.Drop(ctx)
+		client.Disconnect(ctx)
+	})
+	if _, err := dao.SyncIndexes(ctx, database); err != nil {
+		t.Fatal(err)
+	}
+	return database
+}
+
+func TestMongoUserDAO(t *testing.T) {
+	if err := daotest.TestUserDAO(context.Background(), dao.NewUserDAO(testDatabase(t))); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMongoCourseDAO(t *testing.T) {
+	if err := daotest.TestCourseDAO(context.Background(), dao.NewCourseDAO(testDatabase(t))); err != nil {
+		t.Error(err)
+	}
+}
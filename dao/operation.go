@@ -0,0 +1,101 @@
+package dao
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/lucasfloriani/go-mongo/app"
+	mongodb "github.com/lucasfloriani/go-mongo/db"
+	"github.com/lucasfloriani/go-mongo/metrics"
+	"github.com/lucasfloriani/go-mongo/tracing"
+
+	"github.com/mongodb/mongo-go-driver/bson"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// operation measures a DAO operation for the metrics and traces
+type operation struct {
+	collection string
+	name       string
+	start      time.Time
+	span       trace.Span
+}
+
+// newOperation starts measuring the named operation over the collection, returning the
+// context with the operation span. The filter is recorded in the span without its values.
+func newOperation(ctx context.Context, collection, name string, filter *bson.Document) (context.Context, operation) {
+	ctx, span := tracing.Start(ctx, "mongo."+collection+"."+name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "mongodb"),
+			attribute.String("db.mongodb.collection", collection),
+			attribute.String("db.operation", name),
+			attribute.String("db.statement", sanitize(filter)),
+		),
+	)
+	return ctx, operation{collection, name, time.Now(), span}
+}
+
+// done records the operation result with the number of documents it returned
+func (op operation) done(err error, documents int) {
+	metrics.ObserveDAO(op.collection, op.name, time.Since(op.start), err, documents)
+	op.span.SetAttributes(attribute.Int("db.documents", documents))
+	tracing.End(op.span, err)
+}
+
+// documents returns the number of documents read by a single document operation
+func documents(err error) int {
+	if err != nil {
+		return 0
+	}
+	return 1
+}
+
+// sanitize returns the filter keys with every value replaced by "?",
+// so names, phones and other personal data don't leak into the traces
+func sanitize(filter *bson.Document) string {
+	if filter == nil {
+		return "{}"
+	}
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	itr := filter.Iterator()
+	for i := 0; itr.Next(); i++ {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		elem := itr.Element()
+		buf.WriteString(strconv.Quote(elem.Key()) + ": ")
+		if doc, ok := elem.Value().MutableDocumentOK(); ok {
+			buf.WriteString(sanitize(doc))
+		} else {
+			buf.WriteString("?")
+		}
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
+// retryRead runs the idempotent read again while it fails with transient network or election
+// errors, up to app.Config.Database.Retry.ReadAttempts attempts
+func retryRead(ctx context.Context, read func() error) error {
+	config := app.Config.Database.Retry
+	backoff := config.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := read()
+		if err == nil || attempt >= config.ReadAttempts || !mongodb.IsTransient(err) {
+			return err
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return err
+		}
+		if backoff *= 2; backoff > config.MaxBackoff {
+			backoff = config.MaxBackoff
+		}
+	}
+}
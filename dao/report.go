@@ -0,0 +1,258 @@
+package dao
+
+import (
+	"context"
+
+	mongodb "github.com/lucasfloriani/go-mongo/db"
+	"github.com/lucasfloriani/go-mongo/model"
+	"github.com/mongodb/mongo-go-driver/bson"
+	"github.com/mongodb/mongo-go-driver/bson/objectid"
+	"github.com/mongodb/mongo-go-driver/mongo"
+)
+
+// ReportDAO aggregates the user collection with pipelines, without changing it.
+type ReportDAO struct {
+	db *mongo.Collection
+}
+
+// NewReportDAO creates a new ReportDAO
+func NewReportDAO(db *mongo.Database) *ReportDAO {
+	return &ReportDAO{db.Collection("user", mongodb.CollectionOptions("user")...)}
+}
+
+// Enrollments returns the number of users enrolled in each course, most enrolled first.
+func (dao *ReportDAO) Enrollments(ctx context.Context, filter model.ReportFilter) (report model.EnrollmentReport, err error) {
+	match, err := dao.match(filter, nil)
+	if err != nil {
+		return nil, err
+	}
+	reset := func() { report = model.EnrollmentReport{} }
+	err = dao.aggregate(ctx, "report_enrollments", reset, func(cur mongo.Cursor) error {
+		var elem model.Enrollment
+		if err := cur.Decode(&elem); err != nil {
+			return err
+		}
+		report = append(report, elem)
+		return nil
+	},
+		bson.VC.DocumentFromElements(bson.EC.SubDocument("$match", match)),
+		bson.VC.DocumentFromElements(bson.EC.String("$unwind", "$courses")),
+		bson.VC.DocumentFromElements(bson.EC.SubDocumentFromElements("$group",
+			bson.EC.String("_id", "$courses._id"),
+			bson.EC.SubDocumentFromElements("course", bson.EC.String("$first", "$courses.name")),
+			bson.EC.SubDocumentFromElements("users", bson.EC.Int32("$sum", 1)),
+		)),
+		bson.VC.DocumentFromElements(bson.EC.SubDocumentFromElements("$sort",
+			bson.EC.Int32("users", -1),
+			bson.EC.Int32("course", 1),
+		)),
+	)
+	return
+}
+
+// Ages returns the number of users in each bucket between the boundaries, which must be
+// sorted. Users younger than the first boundary or as old as the last one aren't counted.
+func (dao *ReportDAO) Ages(ctx context.Context, filter model.ReportFilter, boundaries []uint) (report model.AgeReport, err error) {
+	match, err := dao.match(filter, boundaries)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]*bson.Value, len(boundaries))
+	for i, boundary := range boundaries {
+		values[i] = bson.VC.Int32(int32(boundary))
+	}
+	reset := func() { report = newAgeReport(boundaries) }
+	err = dao.aggregate(ctx, "report_ages", reset, func(cur mongo.Cursor) error {
+		var elem struct {
+			Min   uint `bson:"_id"`
+			Users int  `bson:"users"`
+		}
+		if err := cur.Decode(&elem); err != nil {
+			return err
+		}
+		for i := range report {
+			if report[i].Min == elem.Min {
+				report[i].Users = elem.Users
+			}
+		}
+		return nil
+	},
+		bson.VC.DocumentFromElements(bson.EC.SubDocument("$match", match)),
+		bson.VC.DocumentFromElements(bson.EC.SubDocumentFromElements("$bucket",
+			bson.EC.String("groupBy", "$age"),
+			bson.EC.ArrayFromElements("boundaries", values...),
+			bson.EC.SubDocumentFromElements("output",
+				bson.EC.SubDocumentFromElements("users", bson.EC.Int32("$sum", 1)),
+			),
+		)),
+	)
+	return
+}
+
+// Phones returns the statistics of the number of phones of the users.
+func (dao *ReportDAO) Phones(ctx context.Context, filter model.ReportFilter) (report *model.PhoneReport, err error) {
+	match, err := dao.match(filter, nil)
+	if err != nil {
+		return nil, err
+	}
+	reset := func() { report = &model.PhoneReport{Distribution: []model.PhoneCount{}} }
+	err = dao.aggregate(ctx, "report_phones", reset, func(cur mongo.Cursor) error {
+		var elem struct {
+			Stats []struct {
+				Users   int     `bson:"users"`
+				Phones  int     `bson:"phones"`
+				Average float64 `bson:"average"`
+				Min     int     `bson:"min"`
+				Max     int     `bson:"max"`
+			} `bson:"stats"`
+			Distribution []model.PhoneCount `bson:"distribution"`
+		}
+		if err := cur.Decode(&elem); err != nil {
+			return err
+		}
+		if len(elem.Stats) > 0 {
+			stats := elem.Stats[0]
+			report.Users, report.Phones, report.Average = stats.Users, stats.Phones, stats.Average
+			report.Min, report.Max = stats.Min, stats.Max
+		}
+		report.Distribution = append(report.Distribution, elem.Distribution...)
+		return nil
+	},
+		bson.VC.DocumentFromElements(bson.EC.SubDocument("$match", match)),
+		bson.VC.DocumentFromElements(bson.EC.SubDocumentFromElements("$project",
+			bson.EC.SubDocumentFromElements("phones",
+				bson.EC.SubDocumentFromElements("$size",
+					bson.EC.ArrayFromElements("$ifNull", bson.VC.String("$phones"), bson.VC.ArrayFromValues()),
+				),
+			),
+		)),
+		bson.VC.DocumentFromElements(bson.EC.SubDocumentFromElements("$facet",
+			bson.EC.ArrayFromElements("stats",
+				bson.VC.DocumentFromElements(bson.EC.SubDocumentFromElements("$group",
+					bson.EC.Null("_id"),
+					bson.EC.SubDocumentFromElements("users", bson.EC.Int32("$sum", 1)),
+					bson.EC.SubDocumentFromElements("phones", bson.EC.String("$sum", "$phones")),
+					bson.EC.SubDocumentFromElements("average", bson.EC.String("$avg", "$phones")),
+					bson.EC.SubDocumentFromElements("min", bson.EC.String("$min", "$phones")),
+					bson.EC.SubDocumentFromElements("max", bson.EC.String("$max", "$phones")),
+				)),
+			),
+			bson.EC.ArrayFromElements("distribution",
+				bson.VC.DocumentFromElements(bson.EC.SubDocumentFromElements("$group",
+					bson.EC.String("_id", "$phones"),
+					bson.EC.SubDocumentFromElements("users", bson.EC.Int32("$sum", 1)),
+				)),
+				bson.VC.DocumentFromElements(bson.EC.SubDocumentFromElements("$sort",
+					bson.EC.Int32("_id", 1),
+				)),
+			),
+		)),
+	)
+	return
+}
+
+// Addresses returns the number of users grouped by the address field (see model.AddressReportFields),
+// most users first. Users without the field are grouped in an empty value.
+func (dao *ReportDAO) Addresses(ctx context.Context, filter model.ReportFilter, field string) (report model.AddressReport, err error) {
+	match, err := dao.match(filter, nil)
+	if err != nil {
+		return nil, err
+	}
+	reset := func() { report = model.AddressReport{} }
+	err = dao.aggregate(ctx, "report_addresses", reset, func(cur mongo.Cursor) error {
+		var elem model.AddressGroup
+		if err := cur.Decode(&elem); err != nil {
+			return err
+		}
+		report = append(report, elem)
+		return nil
+	},
+		bson.VC.DocumentFromElements(bson.EC.SubDocument("$match", match)),
+		bson.VC.DocumentFromElements(bson.EC.SubDocumentFromElements("$group",
+			bson.EC.SubDocumentFromElements("_id",
+				bson.EC.ArrayFromElements("$ifNull", bson.VC.String("$address."+field), bson.VC.String("")),
+			),
+			bson.EC.SubDocumentFromElements("users", bson.EC.Int32("$sum", 1)),
+		)),
+		bson.VC.DocumentFromElements(bson.EC.SubDocumentFromElements("$sort",
+			bson.EC.Int32("users", -1),
+			bson.EC.Int32("_id", 1),
+		)),
+	)
+	return
+}
+
+// match returns the $match of the filter, limited to the ages between the first and the last
+// boundaries when given
+func (dao *ReportDAO) match(filter model.ReportFilter, boundaries []uint) (*bson.Document, error) {
+	match := bson.NewDocument()
+	if filter.CourseID != "" {
+		courseID, err := objectid.FromHex(filter.CourseID)
+		if err != nil {
+			return nil, err
+		}
+		match.Append(bson.EC.ObjectID("courses._id", courseID))
+	}
+
+	minAge, maxAge := ageRange(filter, boundaries)
+	age := bson.NewDocument()
+	if minAge > 0 {
+		age.Append(bson.EC.Int32("$gte", int32(minAge)))
+	}
+	if maxAge > 0 {
+		age.Append(bson.EC.Int32("$lte", int32(maxAge)))
+	}
+	if age.Len() > 0 {
+		match.Append(bson.EC.SubDocument("age", age))
+	}
+	return match, nil
+}
+
+// aggregate runs the pipeline over the user collection, calling decode for each result.
+// reset is called before each attempt, to discard the results of a failed one.
+func (dao *ReportDAO) aggregate(ctx context.Context, name string, reset func(), decode func(cur mongo.Cursor) error, stages ...*bson.Value) (err error) {
+	ctx, op := newOperation(ctx, "user", name, nil)
+	results := 0
+	defer func() { op.done(err, results) }()
+
+	return retryRead(ctx, func() error {
+		results = 0
+		reset()
+		cur, err := dao.db.Aggregate(ctx, bson.NewArray(stages...))
+		if err != nil {
+			return err
+		}
+		defer cur.Close(ctx)
+
+		for cur.Next(ctx) {
+			if err := decode(cur); err != nil {
+				return err
+			}
+			results++
+		}
+		return cur.Err()
+	})
+}
+
+// newAgeReport returns the empty buckets between the boundaries
+func newAgeReport(boundaries []uint) model.AgeReport {
+	report := model.AgeReport{}
+	for i := 0; i+1 < len(boundaries); i++ {
+		report = append(report, model.AgeBucket{Min: boundaries[i], Max: boundaries[i+1]})
+	}
+	return report
+}
+
+// ageRange returns the inclusive ages selected by the filter and the boundaries, 0 when unbounded
+func ageRange(filter model.ReportFilter, boundaries []uint) (minAge, maxAge uint) {
+	minAge, maxAge = filter.MinAge, filter.MaxAge
+	if len(boundaries) > 1 {
+		if first := boundaries[0]; first > minAge {
+			minAge = first
+		}
+		if last := boundaries[len(boundaries)-1] - 1; maxAge == 0 || last < maxAge {
+			maxAge = last
+		}
+	}
+	return
+}
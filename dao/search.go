@@ -0,0 +1,87 @@
+package dao
+
+import (
+	"context"
+
+	"github.com/lucasfloriani/go-mongo/model"
+	"github.com/mongodb/mongo-go-driver/bson"
+	"github.com/mongodb/mongo-go-driver/mongo"
+	"github.com/mongodb/mongo-go-driver/mongo/findopt"
+)
+
+// textLanguage is the language of the text indexes, which selects the stemming and stop words
+const textLanguage = "portuguese"
+
+// textScore is the relevance of the documents found by a full-text search
+type textScore struct {
+	Score float64 `bson:"score"`
+}
+
+// textFilter returns the filter of a full-text search, case and diacritic insensitive with the
+// version 3 text indexes
+func textFilter(query string) *bson.Document {
+	return bson.NewDocument(
+		bson.EC.SubDocumentFromElements("$text",
+			bson.EC.String("$search", query),
+		),
+	)
+}
+
+// textOptions returns the options that add the relevance to the documents, sort them by it
+// and page them with the specified offset and limit
+func textOptions(offset, limit int) []findopt.Find {
+	score := bson.NewDocument(
+		bson.EC.SubDocumentFromElements("score",
+			bson.EC.String("$meta", "textScore"),
+		),
+	)
+	return []findopt.Find{
+		findopt.Projection(score),
+		findopt.Sort(score),
+		findopt.Skip(int64(offset)),
+		findopt.Limit(int64(limit)),
+	}
+}
+
+// findText runs a full-text search over the collection, decoding each document with decode
+func findText(ctx context.Context, coll *mongo.Collection, collection, query string, offset, limit int, decode func(cur mongo.Cursor) (interface{}, error)) (results []model.SearchResult, err error) {
+	filter := textFilter(query)
+	ctx, op := newOperation(ctx, collection, "search", filter)
+	defer func() { op.done(err, len(results)) }()
+
+	err = retryRead(ctx, func() error {
+		results = nil
+		cur, err := coll.Find(ctx, filter, textOptions(offset, limit)...)
+		if err != nil {
+			return err
+		}
+		defer cur.Close(ctx)
+
+		for cur.Next(ctx) {
+			record, err := decode(cur)
+			if err != nil {
+				return err
+			}
+			var score textScore
+			if err := cur.Decode(&score); err != nil {
+				return err
+			}
+			results = append(results, model.SearchResult{Type: collection, Score: score.Score, Record: record})
+		}
+		return cur.Err()
+	})
+	return
+}
+
+// countText returns the number of documents of the collection found by a full-text search
+func countText(ctx context.Context, coll *mongo.Collection, collection, query string) (int, error) {
+	filter := textFilter(query)
+	ctx, op := newOperation(ctx, collection, "count_search", filter)
+	var count int64
+	err := retryRead(ctx, func() (err error) {
+		count, err = coll.Count(ctx, filter)
+		return
+	})
+	op.done(err, 0)
+	return int(count), err
+}
@@ -0,0 +1,46 @@
+package dao
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/lucasfloriani/go-mongo/model"
+	"github.com/lucasfloriani/go-mongo/search"
+	"github.com/mongodb/mongo-go-driver/bson"
+	"github.com/mongodb/mongo-go-driver/mongo"
+	"github.com/mongodb/mongo-go-driver/mongo/findopt"
+)
+
+// suggest finds the documents whose name_normalized starts with the normalized prefix, by
+// name_normalized up to the limit. The anchored regex and the sort use the name_normalized_1
+// index, and only the ID and the name are read.
+func suggest(ctx context.Context, coll *mongo.Collection, collection, prefix string, limit int) (suggestions []model.Suggestion, err error) {
+	filter := bson.NewDocument(
+		bson.EC.Regex("name_normalized", "^"+regexp.QuoteMeta(search.Normalize(prefix)), ""),
+	)
+	ctx, op := newOperation(ctx, collection, "suggest", filter)
+	defer func() { op.done(err, len(suggestions)) }()
+
+	err = retryRead(ctx, func() error {
+		suggestions = nil
+		cur, err := coll.Find(ctx, filter,
+			findopt.Projection(bson.NewDocument(bson.EC.Int32("name", 1))),
+			findopt.Sort(bson.NewDocument(bson.EC.Int32("name_normalized", 1))),
+			findopt.Limit(int64(limit)),
+		)
+		if err != nil {
+			return err
+		}
+		defer cur.Close(ctx)
+
+		for cur.Next(ctx) {
+			var elem model.Suggestion
+			if err := cur.Decode(&elem); err != nil {
+				return err
+			}
+			suggestions = append(suggestions, elem)
+		}
+		return cur.Err()
+	})
+	return
+}
@@ -3,7 +3,9 @@ package dao
 import (
 	"context"
 
+	mongodb "github.com/lucasfloriani/go-mongo/db"
 	"github.com/lucasfloriani/go-mongo/model"
+	"github.com/lucasfloriani/go-mongo/search"
 	"github.com/mongodb/mongo-go-driver/bson"
 	"github.com/mongodb/mongo-go-driver/bson/objectid"
 	"github.com/mongodb/mongo-go-driver/mongo"
@@ -17,7 +19,57 @@ type UserDAO struct {
 
 // NewUserDAO creates a new UserDAO
 func NewUserDAO(db *mongo.Database) *UserDAO {
-	return &UserDAO{db.Collection("user")}
+	return &UserDAO{db.Collection("user", mongodb.CollectionOptions("user")...)}
+}
+
+func (dao *UserDAO) collection() *mongo.Collection {
+	return dao.db
+}
+
+// Indexes returns the indexes of the user collection.
+func (dao *UserDAO) Indexes() []Index {
+	return []Index{
+		{
+			Name: "name_1",
+			Keys: bson.NewDocument(bson.EC.Int32("name", 1)),
+		},
+		{
+			Name: "courses._id_1",
+			Keys: bson.NewDocument(bson.EC.Int32("courses._id", 1)),
+		},
+		{
+			Name: "phones.number_1",
+			Keys: bson.NewDocument(bson.EC.Int32("phones.number", 1)),
+		},
+		{
+			Name: "name_normalized_1",
+			Keys: bson.NewDocument(bson.EC.Int32("name_normalized", 1)),
+		},
+		{
+			Name:          "cpf_1",
+			Keys:          bson.NewDocument(bson.EC.Int32("cpf", 1)),
+			Unique:        true,
+			PartialFilter: bson.NewDocument(bson.EC.SubDocumentFromElements("cpf", bson.EC.Boolean("$exists", true))),
+		},
+		{
+			Name:          "email_1",
+			Keys:          bson.NewDocument(bson.EC.Int32("email", 1)),
+			Unique:        true,
+			PartialFilter: bson.NewDocument(bson.EC.SubDocumentFromElements("email", bson.EC.Boolean("$exists", true))),
+		},
+		{
+			Name: "address.state_1_address.city_1",
+			Keys: bson.NewDocument(
+				bson.EC.Int32("address.state", 1),
+				bson.EC.Int32("address.city", 1),
+			),
+		},
+		{
+			Name:            "name_text",
+			Keys:            bson.NewDocument(bson.EC.String("name", "text")),
+			DefaultLanguage: textLanguage,
+		},
+	}
 }
 
 func (dao *UserDAO) filter(offset, limit int) []findopt.Find {
@@ -29,61 +81,153 @@ func (dao *UserDAO) filter(offset, limit int) []findopt.Find {
 }
 
 // All retrieves the user records with the specified offset and limit from the database.
-func (dao *UserDAO) All(offset, limit int) (elements []model.User, err error) {
-	cur, err := dao.db.Find(context.Background(), nil, dao.filter(offset, limit)...)
-	if err != nil {
-		return
+func (dao *UserDAO) All(ctx context.Context, offset, limit int) ([]model.User, error) {
+	return dao.find(ctx, "all", nil, offset, limit)
+}
+
+// Count returns the number of the user records in the database.
+func (dao *UserDAO) Count(ctx context.Context) (int, error) {
+	return dao.count(ctx, "count", nil)
+}
+
+// ByPhone retrieves the users with the phone number, in the E.164 format, with the specified offset and limit.
+func (dao *UserDAO) ByPhone(ctx context.Context, number string, offset, limit int) ([]model.User, error) {
+	return dao.find(ctx, "by_phone", phoneFilter(number), offset, limit)
+}
+
+// CountByPhone returns the number of users with the phone number, in the E.164 format.
+func (dao *UserDAO) CountByPhone(ctx context.Context, number string) (int, error) {
+	return dao.count(ctx, "count_by_phone", phoneFilter(number))
+}
+
+// phoneFilter matches the users with the phone number
+func phoneFilter(number string) *bson.Document {
+	return bson.NewDocument(
+		bson.EC.String("phones.number", number),
+	)
+}
+
+// findFilter returns the filter for the driver, keeping nil untyped since the driver
+// only takes an untyped nil as the empty filter
+func findFilter(filter *bson.Document) interface{} {
+	if filter == nil {
+		return nil
 	}
-	defer cur.Close(context.Background())
+	return filter
+}
+
+// find retrieves the users matching the filter, nil for every user, with the specified offset and limit
+func (dao *UserDAO) find(ctx context.Context, name string, filter *bson.Document, offset, limit int) (elements []model.User, err error) {
+	ctx, op := newOperation(ctx, "user", name, filter)
+	defer func() { op.done(err, len(elements)) }()
 
-	var elem model.User
-	for cur.Next(context.Background()) {
-		if err = cur.Decode(&elem); err != nil {
-			return
+	err = retryRead(ctx, func() error {
+		elements = nil
+		cur, err := dao.db.Find(ctx, findFilter(filter), dao.filter(offset, limit)...)
+		if err != nil {
+			return err
 		}
-		elements = append(elements, elem)
-	}
+		defer cur.Close(ctx)
+
+		var elem model.User
+		for cur.Next(ctx) {
+			if err := cur.Decode(&elem); err != nil {
+				return err
+			}
+			elements = append(elements, elem)
+		}
+		return cur.Err()
+	})
 
 	return
 }
 
-// Count returns the number of the user records in the database.
-func (dao *UserDAO) Count() (int, error) {
-	count, err := dao.db.Count(context.Background(), nil)
+// count returns the number of users matching the filter, nil for every user
+func (dao *UserDAO) count(ctx context.Context, name string, filter *bson.Document) (int, error) {
+	ctx, op := newOperation(ctx, "user", name, filter)
+	var count int64
+	err := retryRead(ctx, func() (err error) {
+		count, err = dao.db.Count(ctx, findFilter(filter))
+		return
+	})
+	op.done(err, 0)
 	return int(count), err
 }
 
+// Search retrieves the users matching the full-text query, by relevance, with the specified offset and limit.
+func (dao *UserDAO) Search(ctx context.Context, query string, offset, limit int) ([]model.SearchResult, error) {
+	return findText(ctx, dao.db, "user", query, offset, limit, func(cur mongo.Cursor) (interface{}, error) {
+		var elem model.User
+		err := cur.Decode(&elem)
+		return elem, err
+	})
+}
+
+// CountSearch returns the number of the users matching the full-text query.
+func (dao *UserDAO) CountSearch(ctx context.Context, query string) (int, error) {
+	return countText(ctx, dao.db, "user", query)
+}
+
+// Suggest retrieves the users whose name starts with the prefix, ignoring case and diacritics,
+// by name up to the limit.
+func (dao *UserDAO) Suggest(ctx context.Context, prefix string, limit int) ([]model.Suggestion, error) {
+	return suggest(ctx, dao.db, "user", prefix, limit)
+}
+
 // Get reads the user with the specified ID from the database.
-func (dao *UserDAO) Get(id string) (*model.User, error) {
+func (dao *UserDAO) Get(ctx context.Context, id string) (*model.User, error) {
 	objID, err := objectid.FromHex(id)
 	if err != nil {
 		return nil, err
 	}
+	filter := bson.NewDocument(
+		bson.EC.ObjectID("_id", objID),
+	)
+	return dao.getBy(ctx, "get", filter)
+}
+
+// GetByCPF reads the user with the specified CPF, normalized.
+func (dao *UserDAO) GetByCPF(ctx context.Context, cpf model.CPF) (*model.User, error) {
+	return dao.getBy(ctx, "get_by_cpf", bson.NewDocument(
+		bson.EC.String("cpf", string(cpf)),
+	))
+}
+
+// GetByEmail reads the user with the specified email, normalized.
+func (dao *UserDAO) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	return dao.getBy(ctx, "get_by_email", bson.NewDocument(
+		bson.EC.String("email", email),
+	))
+}
+
+// getBy reads the user matching the filter in the operation name
+func (dao *UserDAO) getBy(ctx context.Context, name string, filter *bson.Document) (*model.User, error) {
+	ctx, op := newOperation(ctx, "user", name, filter)
 	u := model.NewUser()
-	err = dao.db.FindOne(
-		context.Background(),
-		bson.NewDocument(
-			bson.EC.ObjectID("_id", objID),
-		),
-	).Decode(u)
+	err := retryRead(ctx, func() error {
+		return dao.db.FindOne(ctx, filter).Decode(u)
+	})
+	op.done(err, documents(err))
 	return u, err
 }
 
 // Create saves a new user record in the database.
 // The User.Id field will be populated with an automatically generated ID upon successful saving.
-func (dao *UserDAO) Create(u *model.User) error {
+func (dao *UserDAO) Create(ctx context.Context, u *model.User) error {
+	ctx, op := newOperation(ctx, "user", "create", nil)
+	identity, _ := dao.getIdentity(u)
 	res, err := dao.db.InsertOne(
-		context.Background(),
+		ctx,
 		bson.NewDocument(
 			bson.EC.String("name", u.Name),
+			bson.EC.String("name_normalized", search.Normalize(u.Name)),
 			bson.EC.Int32("age", int32(u.Age)),
-			bson.EC.SubDocumentFromElements("address",
-				bson.EC.String("name", u.Address.Name),
-			),
+			bson.EC.SubDocumentFromElements("address", dao.getAddress(u)...),
 			bson.EC.ArrayFromElements("phones", dao.getPhones(u)...),
 			bson.EC.ArrayFromElements("courses", dao.getCourses(u)...),
-		),
+		).Append(identity...),
 	)
+	op.done(err, 0)
 	if err != nil {
 		return err
 	}
@@ -92,43 +236,84 @@ func (dao *UserDAO) Create(u *model.User) error {
 }
 
 // Update saves the changes to an user in the database.
-func (dao *UserDAO) Update(u *model.User) error {
-	_, err := dao.db.UpdateOne(
-		context.Background(),
-		bson.NewDocument(
-			bson.EC.ObjectID("_id", u.ID),
-		),
-		bson.NewDocument(
-			bson.EC.SubDocumentFromElements("$set",
+func (dao *UserDAO) Update(ctx context.Context, u *model.User) error {
+	filter := bson.NewDocument(
+		bson.EC.ObjectID("_id", u.ID),
+	)
+	ctx, op := newOperation(ctx, "user", "update", filter)
+	identity, unset := dao.getIdentity(u)
+	update := bson.NewDocument(
+		bson.EC.SubDocumentFromElements("$set",
+			append([]*bson.Element{
 				bson.EC.String("name", u.Name),
+				bson.EC.String("name_normalized", search.Normalize(u.Name)),
 				bson.EC.Int32("age", int32(u.Age)),
-				bson.EC.SubDocumentFromElements("address",
-					bson.EC.String("name", u.Address.Name),
-				),
+				bson.EC.SubDocumentFromElements("address", dao.getAddress(u)...),
 				bson.EC.ArrayFromElements("phones", dao.getPhones(u)...),
 				bson.EC.ArrayFromElements("courses", dao.getCourses(u)...),
-			),
+			}, identity...)...,
 		),
 	)
+	// Mongo rejects an empty $unset
+	if len(unset) > 0 {
+		update.Append(bson.EC.SubDocumentFromElements("$unset", unset...))
+	}
+	_, err := dao.db.UpdateOne(ctx, filter, update)
+	op.done(err, 0)
 	return err
 }
 
 // Delete deletes an user with the specified ID from the database.
-func (dao *UserDAO) Delete(u *model.User) error {
-	_, err := dao.db.DeleteOne(
-		context.Background(),
-		bson.NewDocument(
-			bson.EC.ObjectID("_id", u.ID),
-		),
+func (dao *UserDAO) Delete(ctx context.Context, u *model.User) error {
+	filter := bson.NewDocument(
+		bson.EC.ObjectID("_id", u.ID),
 	)
+	ctx, op := newOperation(ctx, "user", "delete", filter)
+	_, err := dao.db.DeleteOne(ctx, filter)
+	op.done(err, 0)
 	return err
 }
 
+// getIdentity returns the CPF and the email to set, and the empty ones to unset,
+// they are left out of the documents when empty so the unique indexes ignore them
+func (dao *UserDAO) getIdentity(u *model.User) (set, unset []*bson.Element) {
+	fields := []struct {
+		name, value string
+	}{
+		{"cpf", string(u.CPF)},
+		{"email", u.Email},
+	}
+	for _, field := range fields {
+		if field.value == "" {
+			unset = append(unset, bson.EC.String(field.name, ""))
+		} else {
+			set = append(set, bson.EC.String(field.name, field.value))
+		}
+	}
+	return
+}
+
+func (dao *UserDAO) getAddress(u *model.User) []*bson.Element {
+	return []*bson.Element{
+		bson.EC.String("street", u.Address.Street),
+		bson.EC.String("number", u.Address.Number),
+		bson.EC.String("complement", u.Address.Complement),
+		bson.EC.String("neighborhood", u.Address.Neighborhood),
+		bson.EC.String("city", u.Address.City),
+		bson.EC.String("state", u.Address.State),
+		bson.EC.String("cep", u.Address.CEP),
+		bson.EC.String("country", u.Address.Country),
+	}
+}
+
 func (dao *UserDAO) getPhones(u *model.User) (elems []*bson.Value) {
 	for _, phone := range u.Phones {
 		elems = append(elems,
 			bson.VC.DocumentFromElements(
 				bson.EC.String("number", phone.Number),
+				bson.EC.String("type", phone.Type),
+				bson.EC.Boolean("primary", phone.Primary),
+				bson.EC.String("country", phone.Country),
 			),
 		)
 	}
@@ -2,38 +2,66 @@ package db
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"time"
 
 	"github.com/lucasfloriani/go-mongo/app"
 
+	"github.com/mongodb/mongo-go-driver/bson"
 	"github.com/mongodb/mongo-go-driver/mongo"
 )
 
-// Connect to database and returns the connection
-func Connect() *mongo.Database {
-	databaseName, connectionString := selectDatabase()
-	client, err := mongo.NewClient(connectionString)
+// Connect to database and returns the client, to be disconnected on shutdown, and the database.
+// The connection is verified with a ping, retried with exponential backoff until the
+// app.Config.Database.Retry.Deadline passes, so the application can start before Mongo is ready.
+func Connect(ctx context.Context) (*mongo.Client, *mongo.Database, error) {
+	config := app.Config.Database
+	logger := app.Logger("db")
+
+	opts, err := clientOptions()
 	if err != nil {
-		log.Fatal(err)
+		return nil, nil, fmt.Errorf("Invalid client options: %s", err)
 	}
-	err = client.Connect(context.Background())
+	client, err := mongo.NewClientWithOptions(config.Connection, opts...)
 	if err != nil {
-		log.Fatal(err)
+		return nil, nil, fmt.Errorf("Invalid connection string: %s", err)
 	}
-	return client.Database(databaseName)
-}
+	if err := client.Connect(ctx); err != nil {
+		return nil, nil, fmt.Errorf("Failed to connect to the database: %s", err)
+	}
+	database := client.Database(config.Database)
 
-// selectDatabase by connection environment from flag option
-func selectDatabase() (string, string) {
-	switch app.Config.Environment {
-	case "test":
-		test := app.Config.Database.Test
-		return test.Database, test.Connection
-	case "development":
-		dev := app.Config.Database.Development
-		return dev.Database, dev.Connection
-	default:
-		prod := app.Config.Database.Production
-		return prod.Database, prod.Connection
+	deadline := time.Now().Add(config.Retry.Deadline)
+	backoff := config.Retry.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		pingCtx, cancel := context.WithTimeout(ctx, config.ConnectTimeout)
+		err = Ping(pingCtx, database)
+		cancel()
+		if err == nil {
+			logger.Debug("connected", "database", config.Database, "environment", app.Config.Environment, "attempts", attempt)
+			return client, database, nil
+		}
+		if time.Now().Add(backoff).After(deadline) {
+			client.Disconnect(ctx)
+			return nil, nil, fmt.Errorf("Failed to connect to the database after %d attempts: %s", attempt, err)
+		}
+
+		logger.Warn("database not ready, retrying", "attempt", attempt, "backoff", backoff, "error", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			client.Disconnect(context.Background())
+			return nil, nil, ctx.Err()
+		}
+		backoff *= 2
+		if backoff > config.Retry.MaxBackoff {
+			backoff = config.Retry.MaxBackoff
+		}
 	}
 }
+
+// Ping check if the database is reachable
+func Ping(ctx context.Context, database *mongo.Database) error {
+	_, err := database.RunCommand(ctx, bson.NewDocument(bson.EC.Int32("ping", 1)))
+	return err
+}
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"net"
+
+	"github.com/mongodb/mongo-go-driver/core/command"
+	"github.com/mongodb/mongo-go-driver/core/topology"
+	"github.com/mongodb/mongo-go-driver/mongo"
+)
+
+// DuplicateKeyCode is the code returned by Mongo when a unique index is violated
+const DuplicateKeyCode = 11000
+
+// transientCodes are the server error codes returned while the replica set elects a new
+// primary or a node shuts down, the same operation is expected to work when retried
+var transientCodes = map[int32]bool{
+	6:     true, // HostUnreachable
+	7:     true, // HostNotFound
+	89:    true, // NetworkTimeout
+	91:    true, // ShutdownInProgress
+	189:   true, // PrimarySteppedDown
+	9001:  true, // SocketException
+	10107: true, // NotMaster
+	11600: true, // InterruptedAtShutdown
+	11602: true, // InterruptedDueToReplStateChange
+	13435: true, // NotMasterNoSlaveOk
+	13436: true, // NotMasterOrSecondary
+}
+
+// IsTransient check if the error was caused by a network failure or a replica set election.
+func IsTransient(err error) bool {
+	switch e := err.(type) {
+	case command.Error:
+		return transientCodes[e.Code] || e.HasErrorLabel(command.NetworkError)
+	case net.Error:
+		return true
+	}
+	return err == topology.ErrServerSelectionTimeout
+}
+
+// IsDuplicateKey check if the error was caused by a unique index violation.
+func IsDuplicateKey(err error) bool {
+	if writeErrors, ok := err.(mongo.WriteErrors); ok {
+		for _, writeError := range writeErrors {
+			if writeError.Code == DuplicateKeyCode {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"strconv"
+
+	"github.com/lucasfloriani/go-mongo/app"
+
+	"github.com/mongodb/mongo-go-driver/core/readconcern"
+	"github.com/mongodb/mongo-go-driver/core/readpref"
+	"github.com/mongodb/mongo-go-driver/core/writeconcern"
+	"github.com/mongodb/mongo-go-driver/mongo/clientopt"
+	"github.com/mongodb/mongo-go-driver/mongo/collectionopt"
+)
+
+// clientOptions returns the client options set in app.Config.Database
+func clientOptions() ([]clientopt.Option, error) {
+	config := app.Config.Database
+	opts := []clientopt.Option{clientopt.AppName(config.AppName)}
+
+	if config.TLS.Enabled {
+		opts = append(opts, clientopt.SSL(&clientopt.SSLOpt{
+			Enabled:                  true,
+			CaFile:                   config.TLS.CAFile,
+			ClientCertificateKeyFile: config.TLS.CertKeyFile,
+			Insecure:                 config.TLS.Insecure,
+		}))
+	}
+	if config.Auth.Username != "" || config.Auth.Mechanism != "" {
+		opts = append(opts, clientopt.Auth(clientopt.Credential{
+			AuthMechanism: config.Auth.Mechanism,
+			AuthSource:    config.Auth.Source,
+			Username:      config.Auth.Username,
+			Password:      config.Auth.Password,
+		}))
+	}
+	if config.MaxPoolSize > 0 {
+		opts = append(opts, clientopt.MaxConnsPerHost(config.MaxPoolSize))
+	}
+	if config.ConnectTimeout > 0 {
+		opts = append(opts, clientopt.ConnectTimeout(config.ConnectTimeout))
+	}
+	if config.ServerSelectionTimeout > 0 {
+		opts = append(opts, clientopt.ServerSelectionTimeout(config.ServerSelectionTimeout))
+	}
+	if config.SocketTimeout > 0 {
+		opts = append(opts, clientopt.SocketTimeout(config.SocketTimeout))
+	}
+
+	concerns := config.Concerns
+	if concerns.ReadPreference != "" {
+		rp, err := readPreference(concerns.ReadPreference)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, clientopt.ReadPreference(rp))
+	}
+	if concerns.ReadConcern != "" {
+		opts = append(opts, clientopt.ReadConcern(readconcern.New(readconcern.Level(concerns.ReadConcern))))
+	}
+	if concerns.WriteConcern != "" {
+		opts = append(opts, clientopt.WriteConcern(writeConcern(concerns)))
+	}
+	return opts, nil
+}
+
+// CollectionOptions returns the concerns set for the collection in app.Config.Database.Collections,
+// the collections without overrides use the ones of the client.
+// The concerns are validated by LoadConfig, so invalid ones are ignored here.
+func CollectionOptions(collection string) []collectionopt.Option {
+	concerns, ok := app.Config.Database.Collections[collection]
+	if !ok {
+		return nil
+	}
+	var opts []collectionopt.Option
+	if rp, err := readPreference(concerns.ReadPreference); concerns.ReadPreference != "" && err == nil {
+		opts = append(opts, collectionopt.ReadPreference(rp))
+	}
+	if concerns.ReadConcern != "" {
+		opts = append(opts, collectionopt.ReadConcern(readconcern.New(readconcern.Level(concerns.ReadConcern))))
+	}
+	if concerns.WriteConcern != "" {
+		opts = append(opts, collectionopt.WriteConcern(writeConcern(concerns)))
+	}
+	return opts
+}
+
+// readPreference creates the read preference of the given mode
+func readPreference(mode string) (*readpref.ReadPref, error) {
+	m, err := readpref.ModeFromString(mode)
+	if err != nil {
+		return nil, err
+	}
+	return readpref.New(m)
+}
+
+// writeConcern creates the write concern of the given concerns
+func writeConcern(concerns app.ConcernsConfig) *writeconcern.WriteConcern {
+	opts := []writeconcern.Option{writeconcern.J(concerns.WriteJournal)}
+	if concerns.WriteConcern == "majority" {
+		opts = append(opts, writeconcern.WMajority())
+	} else {
+		w, _ := strconv.Atoi(concerns.WriteConcern)
+		opts = append(opts, writeconcern.W(w))
+	}
+	if concerns.WriteTimeout > 0 {
+		opts = append(opts, writeconcern.WTimeout(concerns.WriteTimeout))
+	}
+	return writeconcern.New(opts...)
+}
@@ -0,0 +1,19 @@
+package e2e_test
+
+import (
+	"testing"
+
+	"github.com/lucasfloriani/go-mongo/e2e"
+)
+
+func TestMemory(t *testing.T) {
+	e2e.Run(t, e2e.Options{Backend: e2e.Memory})
+}
+
+// TestMongo runs against GOMONGO_E2E_MONGO or a local mongod, it's skipped without both
+func TestMongo(t *testing.T) {
+	if testing.Short() {
+		t.Skip("e2e: Mongo skipped in short mode")
+	}
+	e2e.Run(t, e2e.Options{Backend: e2e.Mongo})
+}
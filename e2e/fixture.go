@@ -0,0 +1,57 @@
+package e2e
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/lucasfloriani/go-mongo/model"
+)
+
+// Fixture is the data created before a test, through the API so it's validated like in production.
+type Fixture struct {
+	Courses []model.Course
+	Users   []model.User
+}
+
+// Seed creates the courses and then the users of the fixture, filling their IDs.
+func (h *Harness) Seed(f *Fixture) {
+	h.t.Helper()
+	for i := range f.Courses {
+		h.POST("/v1/course/", f.Courses[i]).Expect().Status(http.StatusCreated).Decode(&f.Courses[i])
+	}
+	for i := range f.Users {
+		h.POST("/v1/user/", f.Users[i]).Expect().Status(http.StatusCreated).Decode(&f.Users[i])
+	}
+}
+
+// NewCourse returns a valid course, named by n.
+func NewCourse(n int) model.Course {
+	return model.Course{
+		Name: "Curso " + strconv.Itoa(n),
+		Link: "https://example.com/cursos/" + strconv.Itoa(n),
+	}
+}
+
+// NewAddress returns a valid and normalized address, numbered by n.
+func NewAddress(n int) model.Address {
+	return model.Address{
+		Street:       "Rua das Flores",
+		Number:       strconv.Itoa(n),
+		Neighborhood: "Centro",
+		City:         "São Paulo",
+		State:        "SP",
+		CEP:          "01001-000",
+		Country:      "Brasil",
+	}
+}
+
+// NewUser returns a valid user, named by n.
+func NewUser(n int) model.User {
+	return model.User{
+		Name:    "Usuário " + strconv.Itoa(n),
+		Age:     18 + uint(n%50),
+		Address: NewAddress(n),
+		Phones:  []model.Phone{{Number: "(11) 91234-5678", Type: model.PhoneMobile, Primary: true}},
+		Courses: []model.Course{},
+	}
+}
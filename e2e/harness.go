@@ -0,0 +1,168 @@
+// Package e2e boots the API from router.Setup in-process and offers fluent helpers to send
+// requests and assert on the helper.Response envelopes, for end-to-end tests of the v1 API.
+//
+// A test creates a Harness with the backend to run against and sends requests with it:
+//
+//	h := e2e.New(t, e2e.Options{Backend: e2e.Memory})
+//	h.GET("/v1/course/").Expect().Status(http.StatusFound).Page(1, 10, 0, 0, 0)
+//
+// Run runs the whole suite of the v1 API against a backend.
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/lucasfloriani/go-mongo/app"
+	"github.com/lucasfloriani/go-mongo/dao"
+	"github.com/lucasfloriani/go-mongo/db"
+	"github.com/lucasfloriani/go-mongo/router"
+
+	"github.com/labstack/echo"
+	"github.com/mongodb/mongo-go-driver/bson/objectid"
+	"github.com/mongodb/mongo-go-driver/mongo"
+)
+
+// Backend selects where the harness keeps the data.
+type Backend string
+
+const (
+	// Memory keeps the data in the memory DAOs
+	Memory Backend = "memory"
+	// Mongo keeps the data in Mongo, at the connection string of the GOMONGO_E2E_MONGO
+	// environment variable or else in a mongod started by the harness
+	Mongo Backend = "mongo"
+)
+
+// Options configures a Harness.
+type Options struct {
+	// Backend defaults to Memory
+	Backend Backend
+	// ConfigDir is the directory of app.yaml. Defaults to the nearest config directory
+	// in the working directory or its parents
+	ConfigDir string
+	// Role is the role of the requests sent without an API key. Defaults to "admin"
+	Role string
+}
+
+// Harness runs the API of router.Setup in-process, loaded with the "test" environment.
+type Harness struct {
+	t        testing.TB
+	options  Options
+	client   *mongo.Client
+	database *mongo.Database
+	// Echo is the application under test, recreated by Reset
+	Echo *echo.Echo
+}
+
+// New creates a Harness, failing the test when the backend can't be started.
+// The backend is stopped when the test finishes.
+func New(t testing.TB, options Options) *Harness {
+	t.Helper()
+	if options.Backend == "" {
+		options.Backend = Memory
+	}
+	if options.Role == "" {
+		options.Role = "admin"
+	}
+	if options.ConfigDir == "" {
+		options.ConfigDir = findConfigDir(t)
+	}
+
+	t.Setenv(app.EnvPrefix+"_ENVIRONMENT", "test")
+	t.Setenv(app.EnvPrefix+"_STORAGE", string(options.Backend))
+	t.Setenv(app.EnvPrefix+"_AUTHORIZATION_ANONYMOUS_ROLE", options.Role)
+	t.Setenv(app.EnvPrefix+"_DATABASE_DATABASE", "e2e_"+objectid.New().Hex())
+	if options.Backend == Mongo {
+		connection := os.Getenv("GOMONGO_E2E_MONGO")
+		if connection == "" {
+			connection = startMongod(t)
+		}
+		t.Setenv(app.EnvPrefix+"_DATABASE_CONNECTION", connection)
+	}
+	if err := app.LoadConfig(options.ConfigDir); err != nil {
+		t.Fatalf("e2e: invalid configuration: %s", err)
+	}
+	app.InitLogger()
+
+	h := &Harness{t: t, options: options}
+	if options.Backend == Mongo {
+		client, database, err := db.Connect(context.Background())
+		if err != nil {
+			t.Fatalf("e2e: failed to connect to the database: %s", err)
+		}
+		h.client, h.database = client, database
+		t.Cleanup(func() {
+			database.Drop(context.Background())
+			client.Disconnect(context.Background())
+		})
+	}
+	h.Reset()
+	return h
+}
+
+// Reset discards the data of the backend, starting again with a new application.
+func (h *Harness) Reset() {
+	h.t.Helper()
+	if h.database != nil {
+		if err := h.database.Drop(context.Background()); err != nil {
+			h.t.Fatalf("e2e: failed to drop the database: %s", err)
+		}
+		if _, err := dao.SyncIndexes(context.Background(), h.database); err != nil {
+			h.t.Fatalf("e2e: failed to sync the indexes: %s", err)
+		}
+	}
+	h.Echo = router.Setup(h.database)
+}
+
+// findConfigDir returns the nearest config directory with an app.yaml
+func findConfigDir(t testing.TB) string {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("e2e: %s", err)
+	}
+	for {
+		config := filepath.Join(dir, "config")
+		if _, err := os.Stat(filepath.Join(config, "app.yaml")); err == nil {
+			return config
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			t.Fatalf("e2e: config/app.yaml not found, set Options.ConfigDir")
+		}
+		dir = parent
+	}
+}
+
+// startMongod starts a mongod listening on a free local port with a temporary data directory,
+// skipping the test when mongod isn't installed. It's stopped when the test finishes.
+func startMongod(t testing.TB) string {
+	path, err := exec.LookPath("mongod")
+	if err != nil {
+		t.Skip("e2e: mongod not found, install it or set GOMONGO_E2E_MONGO")
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("e2e: %s", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	cmd := exec.Command(path, "--dbpath", t.TempDir(), "--bind_ip", "127.0.0.1", "--port", strconv.Itoa(port))
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("e2e: failed to start mongod: %s", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	// db.Connect retries until mongod accepts connections
+	return fmt.Sprintf("mongodb://127.0.0.1:%d", port)
+}
@@ -0,0 +1,213 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lucasfloriani/go-mongo/auth"
+	"github.com/lucasfloriani/go-mongo/helper"
+
+	"github.com/labstack/echo"
+)
+
+// Request is a request to the application under test, sent by Expect.
+type Request struct {
+	h      *Harness
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+	body   []byte
+}
+
+// GET creates a GET request to the path.
+func (h *Harness) GET(path string) *Request {
+	return h.NewRequest(http.MethodGet, path, nil)
+}
+
+// POST creates a POST request to the path with the JSON of body.
+func (h *Harness) POST(path string, body interface{}) *Request {
+	return h.NewRequest(http.MethodPost, path, body)
+}
+
+// PUT creates a PUT request to the path with the JSON of body.
+func (h *Harness) PUT(path string, body interface{}) *Request {
+	return h.NewRequest(http.MethodPut, path, body)
+}
+
+// DELETE creates a DELETE request to the path.
+func (h *Harness) DELETE(path string) *Request {
+	return h.NewRequest(http.MethodDelete, path, nil)
+}
+
+// NewRequest creates a request with the JSON of body, sent as is when it's a string or []byte.
+func (h *Harness) NewRequest(method, path string, body interface{}) *Request {
+	r := &Request{h: h, method: method, path: path, query: url.Values{}, header: http.Header{}}
+	switch b := body.(type) {
+	case nil:
+	case string:
+		r.body = []byte(b)
+	case []byte:
+		r.body = b
+	default:
+		content, err := json.Marshal(body)
+		if err != nil {
+			h.t.Fatalf("e2e: %s %s: %s", method, path, err)
+		}
+		r.body = content
+	}
+	if r.body != nil {
+		r.header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	return r
+}
+
+// Query adds a query parameter.
+func (r *Request) Query(key, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+// Header sets a header.
+func (r *Request) Header(key, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+// APIKey authenticates the request with the API key.
+func (r *Request) APIKey(key string) *Request {
+	return r.Header(auth.APIKeyHeader, key)
+}
+
+// Expect sends the request, returning its response to be asserted.
+func (r *Request) Expect() *Response {
+	r.h.t.Helper()
+	target := r.path
+	if len(r.query) > 0 {
+		target += "?" + r.query.Encode()
+	}
+	req := httptest.NewRequest(r.method, target, bytes.NewReader(r.body))
+	for key, values := range r.header {
+		req.Header[key] = values
+	}
+	rec := httptest.NewRecorder()
+	r.h.Echo.ServeHTTP(rec, req)
+
+	res := &Response{t: r.h.t, name: r.method + " " + target, Recorder: rec}
+	var envelope struct {
+		Error     string          `json:"error"`
+		Response  json.RawMessage `json:"response"`
+		RequestID string          `json:"request_id"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil {
+		r.h.t.Fatalf("e2e: %s: the body isn't a response envelope: %s\n%s", res.name, err, rec.Body.String())
+	}
+	res.raw = envelope.Response
+	res.Envelope = helper.Response{Error: envelope.Error, RequestID: envelope.RequestID}
+	json.Unmarshal(envelope.Response, &res.Envelope.Response)
+	return res
+}
+
+// Response is the response of a request, with fluent assertions that fail the test.
+type Response struct {
+	t    testing.TB
+	name string
+	raw  json.RawMessage
+	// Recorder is the recorded response
+	Recorder *httptest.ResponseRecorder
+	// Envelope is the decoded body
+	Envelope helper.Response
+}
+
+// Status asserts the status code.
+func (r *Response) Status(code int) *Response {
+	r.t.Helper()
+	if r.Recorder.Code != code {
+		r.t.Errorf("%s: status %d, want %d\n%s", r.name, r.Recorder.Code, code, r.Recorder.Body.String())
+	}
+	return r
+}
+
+// Success asserts the response has no error.
+func (r *Response) Success() *Response {
+	r.t.Helper()
+	if r.Envelope.Error != "" {
+		r.t.Errorf("%s: error %q, want none", r.name, r.Envelope.Error)
+	}
+	return r
+}
+
+// Error asserts the error message.
+func (r *Response) Error(message string) *Response {
+	r.t.Helper()
+	if r.Envelope.Error != message {
+		r.t.Errorf("%s: error %q, want %q", r.name, r.Envelope.Error, message)
+	}
+	return r
+}
+
+// ErrorContains asserts the error message has every part, e.g. the messages of the invalid fields.
+func (r *Response) ErrorContains(parts ...string) *Response {
+	r.t.Helper()
+	if r.Envelope.Error == "" {
+		r.t.Errorf("%s: no error, want one with %q", r.name, parts)
+	}
+	for _, part := range parts {
+		if !strings.Contains(r.Envelope.Error, part) {
+			r.t.Errorf("%s: error %q, want it to contain %q", r.name, r.Envelope.Error, part)
+		}
+	}
+	if r.Envelope.RequestID == "" {
+		r.t.Errorf("%s: error without request_id", r.name)
+	}
+	return r
+}
+
+// Decode decodes the response field of the envelope into v.
+func (r *Response) Decode(v interface{}) *Response {
+	r.t.Helper()
+	if err := json.Unmarshal(r.raw, v); err != nil {
+		r.t.Errorf("%s: failed to decode the response into %T: %s", r.name, v, err)
+	}
+	return r
+}
+
+// Equal asserts the response field of the envelope decodes to want, compared as JSON values.
+func (r *Response) Equal(want interface{}) *Response {
+	r.t.Helper()
+	content, err := json.Marshal(want)
+	if err != nil {
+		r.t.Fatalf("e2e: %s", err)
+	}
+	var expected interface{}
+	json.Unmarshal(content, &expected)
+	if !reflect.DeepEqual(r.Envelope.Response, expected) {
+		r.t.Errorf("%s: response %s, want %s", r.name, r.raw, content)
+	}
+	return r
+}
+
+// Page asserts the response is a helper.PaginatedList with the given page, sizes and number of items.
+func (r *Response) Page(page, perPage, pageCount, totalCount, items int) *Response {
+	r.t.Helper()
+	var list struct {
+		helper.PaginatedList
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(r.raw, &list); err != nil {
+		r.t.Errorf("%s: the response isn't a paginated list: %s", r.name, err)
+		return r
+	}
+	got := []int{list.Page, list.PerPage, list.PageCount, list.TotalCount, len(list.Items)}
+	want := []int{page, perPage, pageCount, totalCount, items}
+	if !reflect.DeepEqual(got, want) {
+		r.t.Errorf("%s: page, per_page, page_count, total_count and items %v, want %v", r.name, got, want)
+	}
+	return r
+}
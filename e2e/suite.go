@@ -0,0 +1,140 @@
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/lucasfloriani/go-mongo/model"
+
+	"github.com/mongodb/mongo-go-driver/bson/objectid"
+	"github.com/mongodb/mongo-go-driver/mongo"
+)
+
+// resource describes a CRUD resource of the v1 API for the shared cases
+type resource struct {
+	path string
+	// valid returns a valid body, named by n
+	valid func(n int) interface{}
+	// invalid is a body rejected by the validation, with the expected messages
+	invalid  interface{}
+	messages []string
+}
+
+var resources = map[string]resource{
+	"course": {
+		path:     "/v1/course/",
+		valid:    func(n int) interface{} { return NewCourse(n) },
+		invalid:  model.Course{Name: "Go", Link: "not a link"},
+		messages: []string{"Nome do curso deve estar entre 5 à 50 caracteres", "URL inválida."},
+	},
+	"user": {
+		path:     "/v1/user/",
+		valid:    func(n int) interface{} { return NewUser(n) },
+		invalid:  model.User{Name: "Ana", Age: 17, Address: NewAddress(1), Phones: []model.Phone{{Number: "(11) 91234-5678"}}},
+		messages: []string{"Nome deve estar entre 5 à 50 caracteres", "Idade mínima de 18 anos."},
+	},
+}
+
+// Run runs the suite of the v1 API against the backend of the options, with a subtest
+// for each case and resource. Every case starts with an empty backend.
+func Run(t *testing.T, options Options) {
+	h := New(t, options)
+	cases := []struct {
+		name string
+		run  func(t *testing.T, h *Harness, r resource)
+	}{
+		{"CRUD", testCRUD},
+		{"Pagination", testPagination},
+		{"Validation", testValidation},
+		{"InvalidID", testInvalidID},
+	}
+	for name, r := range resources {
+		for _, c := range cases {
+			t.Run(name+"/"+c.name, func(t *testing.T) {
+				h.t = t
+				h.Reset()
+				c.run(t, h, r)
+			})
+		}
+	}
+}
+
+// testCRUD creates, reads, updates and deletes a record
+func testCRUD(t *testing.T, h *Harness, r resource) {
+	var created map[string]interface{}
+	h.POST(r.path, r.valid(1)).Expect().Status(http.StatusCreated).Success().Decode(&created)
+	id, _ := created["id"].(string)
+	if _, err := objectid.FromHex(id); err != nil {
+		t.Fatalf("created with the ID %q, want an ObjectID", id)
+	}
+
+	h.GET(r.path + id).Expect().Status(http.StatusFound).Success().Equal(created)
+
+	// The ID of the body is ignored, the one of the URL is updated
+	changed := r.valid(2)
+	var updated map[string]interface{}
+	h.PUT(r.path+id, changed).Expect().Status(http.StatusOK).Success().Decode(&updated)
+	if updated["id"] != id || updated["name"] == created["name"] {
+		t.Errorf("updated to %v, want the record %s changed", updated, id)
+	}
+	h.GET(r.path + id).Expect().Status(http.StatusFound).Equal(updated)
+
+	h.DELETE(r.path + id).Expect().Status(http.StatusOK).Success().Equal(updated)
+	h.GET(r.path + id).Expect().Status(http.StatusNotFound).Error(mongo.ErrNoDocuments.Error())
+	h.DELETE(r.path + id).Expect().Status(http.StatusBadRequest).Error(mongo.ErrNoDocuments.Error())
+	h.GET(r.path).Expect().Status(http.StatusFound).Page(1, 10, 0, 0, 0)
+}
+
+// testPagination checks the pages of helper.NewPaginatedList, including the edge cases
+func testPagination(t *testing.T, h *Harness, r resource) {
+	// Empty list
+	h.GET(r.path).Expect().Status(http.StatusFound).Success().Page(1, 10, 0, 0, 0)
+	h.GET(r.path).Query("page", "3").Expect().Page(1, 10, 0, 0, 0)
+
+	for i := 1; i <= 12; i++ {
+		h.POST(r.path, r.valid(i)).Expect().Status(http.StatusCreated)
+	}
+	h.GET(r.path).Expect().Status(http.StatusFound).Page(1, 10, 2, 12, 10)
+	h.GET(r.path).Query("page", "2").Expect().Page(2, 10, 2, 12, 2)
+	h.GET(r.path).Query("page", "2").Query("per_page", "5").Expect().Page(2, 5, 3, 12, 5)
+	h.GET(r.path).Query("page", "3").Query("per_page", "5").Expect().Page(3, 5, 3, 12, 2)
+
+	// Pages beyond the end are the last one, and negative or invalid ones the first
+	h.GET(r.path).Query("page", "99").Query("per_page", "5").Expect().Page(3, 5, 3, 12, 2)
+	h.GET(r.path).Query("page", "-1").Expect().Page(1, 10, 2, 12, 10)
+	h.GET(r.path).Query("page", "first").Expect().Page(1, 10, 2, 12, 10)
+
+	// Page sizes out of range use the default and the max ones
+	h.GET(r.path).Query("per_page", "0").Expect().Page(1, 10, 2, 12, 10)
+	h.GET(r.path).Query("per_page", "-5").Expect().Page(1, 10, 2, 12, 10)
+	h.GET(r.path).Query("per_page", "100").Expect().Page(1, 15, 1, 12, 12)
+}
+
+// testValidation checks that invalid bodies are rejected and nothing is saved
+func testValidation(t *testing.T, h *Harness, r resource) {
+	h.POST(r.path, r.invalid).Expect().Status(http.StatusBadRequest).ErrorContains(r.messages...)
+	h.POST(r.path, "{").Expect().Status(http.StatusBadRequest).ErrorContains()
+	h.POST(r.path, map[string]interface{}{}).Expect().Status(http.StatusBadRequest).ErrorContains()
+	h.GET(r.path).Expect().Page(1, 10, 0, 0, 0)
+
+	var created map[string]interface{}
+	h.POST(r.path, r.valid(1)).Expect().Status(http.StatusCreated).Decode(&created)
+	id, _ := created["id"].(string)
+	h.PUT(r.path+id, r.invalid).Expect().Status(http.StatusBadRequest).ErrorContains(r.messages...)
+	h.PUT(r.path+id, "{").Expect().Status(http.StatusBadRequest).ErrorContains()
+	h.GET(r.path + id).Expect().Status(http.StatusFound).Equal(created)
+}
+
+// testInvalidID checks the IDs that aren't ObjectIDs and the unknown ones
+func testInvalidID(t *testing.T, h *Harness, r resource) {
+	for _, id := range []string{"invalid", "123", "zzzzzzzzzzzzzzzzzzzzzzzz"} {
+		h.GET(r.path + id).Expect().Status(http.StatusNotFound).ErrorContains()
+		h.PUT(r.path+id, r.valid(1)).Expect().Status(http.StatusBadRequest).ErrorContains()
+		h.DELETE(r.path + id).Expect().Status(http.StatusBadRequest).ErrorContains()
+	}
+
+	unknown := objectid.New().Hex()
+	h.GET(r.path + unknown).Expect().Status(http.StatusNotFound).Error(mongo.ErrNoDocuments.Error())
+	h.PUT(r.path+unknown, r.valid(1)).Expect().Status(http.StatusBadRequest).Error(mongo.ErrNoDocuments.Error())
+	h.DELETE(r.path + unknown).Expect().Status(http.StatusBadRequest).Error(mongo.ErrNoDocuments.Error())
+}
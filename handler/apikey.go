@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/lucasfloriani/go-mongo/app"
+	"github.com/lucasfloriani/go-mongo/helper"
+	"github.com/lucasfloriani/go-mongo/model"
+
+	"github.com/labstack/echo"
+)
+
+type (
+	// apiKeyService specifies the interface for the API key service needed by apiKeyResource.
+	apiKeyService interface {
+		Get(rs app.RequestScope, id string) (*model.APIKey, error)
+		Query(rs app.RequestScope, offset, limit int) ([]model.APIKey, error)
+		Count(rs app.RequestScope) (int, error)
+		Create(rs app.RequestScope, model *model.APIKey) (*model.APIKey, error)
+		Rotate(rs app.RequestScope, id string) (*model.APIKey, error)
+		Revoke(rs app.RequestScope, id string) (*model.APIKey, error)
+	}
+
+	// apiKeyInput are the fields of an API key set by the client, the others are set by the server
+	apiKeyInput struct {
+		Name      string    `json:"name"`
+		Scopes    []string  `json:"scopes"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+
+	// apiKeyResource defines the handlers for the API key management APIs.
+	apiKeyResource struct {
+		service apiKeyService
+	}
+)
+
+// ServeAPIKeyResource sets up the routing of API key endpoints and the corresponding handlers (routes)
+func ServeAPIKeyResource(e *echo.Group, service apiKeyService) {
+	at := &apiKeyResource{service}
+	apiKeyGroup := e.Group("/apikey")
+	{
+		apiKeyGroup.GET("/:keyID", at.get)
+		apiKeyGroup.GET("/", at.query)
+		apiKeyGroup.POST("/", at.create)
+		apiKeyGroup.POST("/:keyID/rotate", at.rotate)
+		apiKeyGroup.DELETE("/:keyID", at.revoke)
+	}
+}
+
+// get verify rest params, call service method to execute business logic
+// and return JSON data
+func (r *apiKeyResource) get(c echo.Context) error {
+	response, err := r.service.Get(app.GetRequestScope(c), c.Param("keyID"))
+	if err != nil {
+		return c.JSON(helper.StatusCode(err, http.StatusNotFound), helper.NewRequestErrorResponse(c, err))
+	}
+	return c.JSON(http.StatusFound, helper.NewSuccessResponse(*response))
+}
+
+// query verify rest params, call service method to execute business logic
+// and return JSON data
+func (r *apiKeyResource) query(c echo.Context) error {
+	rs := app.GetRequestScope(c)
+	count, err := r.service.Count(rs)
+	if err != nil {
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
+	}
+
+	paginatedList := helper.GetPaginatedListFromRequest(c, count)
+	items, err := r.service.Query(rs, paginatedList.Offset(), paginatedList.Limit())
+	if err != nil {
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
+	}
+	paginatedList.Items = items
+
+	return c.JSON(http.StatusFound, helper.NewSuccessResponse(paginatedList))
+}
+
+// create call service method to execute business logic
+// and return JSON data with the plaintext key
+func (r *apiKeyResource) create(c echo.Context) error {
+	var input apiKeyInput
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.NewRequestErrorResponse(c, err))
+	}
+	model := model.APIKey{Name: input.Name, Scopes: input.Scopes, ExpiresAt: input.ExpiresAt}
+	response, err := r.service.Create(app.GetRequestScope(c), &model)
+	if err != nil {
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
+	}
+
+	return c.JSON(http.StatusCreated, helper.NewSuccessResponse(*response))
+}
+
+// rotate verify rest params, call service method to execute business logic
+// and return JSON data with the new plaintext key
+func (r *apiKeyResource) rotate(c echo.Context) error {
+	response, err := r.service.Rotate(app.GetRequestScope(c), c.Param("keyID"))
+	if err != nil {
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
+	}
+
+	return c.JSON(http.StatusOK, helper.NewSuccessResponse(*response))
+}
+
+// revoke verify rest params, call service method to execute business logic
+// and return JSON data
+func (r *apiKeyResource) revoke(c echo.Context) error {
+	response, err := r.service.Revoke(app.GetRequestScope(c), c.Param("keyID"))
+	if err != nil {
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
+	}
+
+	return c.JSON(http.StatusOK, helper.NewSuccessResponse(*response))
+}
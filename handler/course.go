@@ -2,7 +2,9 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 
+	"github.com/lucasfloriani/go-mongo/app"
 	"github.com/lucasfloriani/go-mongo/helper"
 	"github.com/lucasfloriani/go-mongo/model"
 
@@ -12,12 +14,15 @@ import (
 type (
 	// courseService specifies the interface for the course service needed by courseResource.
 	courseService interface {
-		Get(id string) (*model.Course, error)
-		Query(offset, limit int) ([]model.Course, error)
-		Count() (int, error)
-		Create(model *model.Course) (*model.Course, error)
-		Update(model *model.Course) (*model.Course, error)
-		Delete(id string) (*model.Course, error)
+		Get(rs app.RequestScope, id string) (*model.Course, error)
+		Query(rs app.RequestScope, offset, limit int) ([]model.Course, error)
+		Count(rs app.RequestScope) (int, error)
+		Search(rs app.RequestScope, query string, offset, limit int) ([]model.SearchResult, error)
+		CountSearch(rs app.RequestScope, query string) (int, error)
+		Suggest(rs app.RequestScope, prefix string, limit int) ([]model.Suggestion, error)
+		Create(rs app.RequestScope, model *model.Course) (*model.Course, error)
+		Update(rs app.RequestScope, model *model.Course) (*model.Course, error)
+		Delete(rs app.RequestScope, id string) (*model.Course, error)
 	}
 
 	// courseResource defines the handlers for the CRUD APIs.
@@ -26,14 +31,16 @@ type (
 	}
 )
 
-// ServeCourseResource sets up the routing of course endpoints and the corresponding handlers (routes)
-func ServeCourseResource(e *echo.Group, service courseService) {
+// ServeCourseResource sets up the routing of course endpoints and the corresponding handlers (routes),
+// createMiddlewares are used only by the create endpoint
+func ServeCourseResource(e *echo.Group, service courseService, createMiddlewares ...echo.MiddlewareFunc) {
 	at := &courseResource{service}
 	courseGroup := e.Group("/course")
 	{
+		courseGroup.GET("/_suggest", at.suggest)
 		courseGroup.GET("/:courseID", at.get)
 		courseGroup.GET("/", at.query)
-		courseGroup.POST("/", at.create)
+		courseGroup.POST("/", at.create, createMiddlewares...)
 		courseGroup.PUT("/:courseID", at.update)
 		courseGroup.DELETE("/:courseID", at.delete)
 	}
@@ -42,41 +49,82 @@ func ServeCourseResource(e *echo.Group, service courseService) {
 // get verify rest params, call service method to execute business logic
 // and return JSON data
 func (r *courseResource) get(c echo.Context) error {
-	response, err := r.service.Get(c.Param("courseID"))
+	response, err := r.service.Get(app.GetRequestScope(c), c.Param("courseID"))
 	if err != nil {
-		return c.JSON(http.StatusNotFound, helper.NewErrorResponse(err))
+		return c.JSON(helper.StatusCode(err, http.StatusNotFound), helper.NewRequestErrorResponse(c, err))
 	}
 	return c.JSON(http.StatusFound, helper.NewSuccessResponse(*response))
 }
 
 // query verify rest params, call service method to execute business logic
-// and return JSON data
+// and return JSON data. With the q param, the full-text search results are returned instead
 func (r *courseResource) query(c echo.Context) error {
-	count, err := r.service.Count()
+	if c.QueryParam("q") != "" {
+		return r.search(c)
+	}
+
+	rs := app.GetRequestScope(c)
+	count, err := r.service.Count(rs)
+	if err != nil {
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
+	}
+
+	paginatedList := helper.GetPaginatedListFromRequest(c, count)
+	items, err := r.service.Query(rs, paginatedList.Offset(), paginatedList.Limit())
+	if err != nil {
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
+	}
+	paginatedList.Items = items
+
+	return c.JSON(http.StatusFound, helper.NewSuccessResponse(paginatedList))
+}
+
+// search verify rest params, call service method to execute business logic
+// and return JSON data
+func (r *courseResource) search(c echo.Context) error {
+	rs := app.GetRequestScope(c)
+	query := c.QueryParam("q")
+	count, err := r.service.CountSearch(rs, query)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, helper.NewErrorResponse(err))
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
 	}
 
 	paginatedList := helper.GetPaginatedListFromRequest(c, count)
-	items, err := r.service.Query(paginatedList.Offset(), paginatedList.Limit())
+	items, err := r.service.Search(rs, query, paginatedList.Offset(), paginatedList.Limit())
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, helper.NewErrorResponse(err))
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
 	}
 	paginatedList.Items = items
 
 	return c.JSON(http.StatusFound, helper.NewSuccessResponse(paginatedList))
 }
 
+// suggest verify rest params, call service method to execute business logic
+// and return JSON data. The suggestions are cached briefly by the client, since they're
+// requested on each keystroke
+func (r *courseResource) suggest(c echo.Context) error {
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	response, err := r.service.Suggest(app.GetRequestScope(c), c.QueryParam("prefix"), limit)
+	if err != nil {
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
+	}
+	if response == nil {
+		response = []model.Suggestion{}
+	}
+	c.Response().Header().Set("Cache-Control", "private, max-age=30")
+	return c.JSON(http.StatusFound, helper.NewSuccessResponse(response))
+}
+
 // create call service method to execute business logic
 // and return JSON data
 func (r *courseResource) create(c echo.Context) error {
 	var model model.Course
 	if err := c.Bind(&model); err != nil {
-		return c.JSON(http.StatusBadRequest, helper.NewErrorResponse(err))
+		return c.JSON(http.StatusBadRequest, helper.NewRequestErrorResponse(c, err))
 	}
-	response, err := r.service.Create(&model)
+	response, err := r.service.Create(app.GetRequestScope(c), &model)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, helper.NewErrorResponse(err))
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
 	}
 
 	return c.JSON(http.StatusCreated, helper.NewSuccessResponse(*response))
@@ -85,18 +133,22 @@ func (r *courseResource) create(c echo.Context) error {
 // update verify rest params, call service method to execute business logic
 // and return JSON data
 func (r *courseResource) update(c echo.Context) error {
-	model, err := r.service.Get(c.Param("courseID"))
+	rs := app.GetRequestScope(c)
+	model, err := r.service.Get(rs, c.Param("courseID"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, helper.NewErrorResponse(err))
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
 	}
 
+	// The ID from the URL is kept so the body can't point the update to another record
+	id := model.ID
 	if err := c.Bind(model); err != nil {
-		return c.JSON(http.StatusBadRequest, helper.NewErrorResponse(err))
+		return c.JSON(http.StatusBadRequest, helper.NewRequestErrorResponse(c, err))
 	}
+	model.ID = id
 
-	response, err := r.service.Update(model)
+	response, err := r.service.Update(rs, model)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, helper.NewErrorResponse(err))
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
 	}
 
 	return c.JSON(http.StatusOK, helper.NewSuccessResponse(*response))
@@ -105,9 +157,9 @@ func (r *courseResource) update(c echo.Context) error {
 // delete verify rest params, call service method to execute business logic
 // and return JSON data
 func (r *courseResource) delete(c echo.Context) error {
-	response, err := r.service.Delete(c.Param("courseID"))
+	response, err := r.service.Delete(app.GetRequestScope(c), c.Param("courseID"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, helper.NewErrorResponse(err))
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
 	}
 
 	return c.JSON(http.StatusOK, helper.NewSuccessResponse(*response))
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/lucasfloriani/go-mongo/app"
+	"github.com/lucasfloriani/go-mongo/health"
+	"github.com/lucasfloriani/go-mongo/helper"
+
+	"github.com/labstack/echo"
+)
+
+type (
+	// healthService specifies the interface for the health service needed by healthResource.
+	healthService interface {
+		Ready(ctx context.Context) health.Report
+		Status(rs app.RequestScope) health.Report
+	}
+
+	// healthResource defines the handlers for the health APIs.
+	healthResource struct {
+		service healthService
+	}
+)
+
+// ServeHealthResource sets up the routing of the liveness and readiness probes,
+// meant to be mounted without authentication
+func ServeHealthResource(e *echo.Echo, service healthService) {
+	at := &healthResource{service}
+	e.GET("/healthz", at.live)
+	e.GET("/readyz", at.ready)
+}
+
+// ServeStatusResource sets up the routing of the status of the application,
+// detailed only for the callers with the status:read permission
+func ServeStatusResource(e *echo.Group, service healthService) {
+	at := &healthResource{service}
+	e.GET("/status", at.status)
+}
+
+// live return that the process is alive, without checking its dependencies
+func (r *healthResource) live(c echo.Context) error {
+	return c.JSON(http.StatusOK, helper.NewSuccessResponse(map[string]string{"status": health.StatusUp}))
+}
+
+// ready call service method to check the dependencies
+// and return JSON data with 503 when any of them is down
+func (r *healthResource) ready(c echo.Context) error {
+	report := r.service.Ready(c.Request().Context())
+	status := http.StatusOK
+	if report.Status != health.StatusUp {
+		status = http.StatusServiceUnavailable
+	}
+	return c.JSON(status, helper.NewSuccessResponse(map[string]string{"status": report.Status}))
+}
+
+// status call service method to check the dependencies
+// and return JSON data with the status of each one
+func (r *healthResource) status(c echo.Context) error {
+	return c.JSON(http.StatusOK, helper.NewSuccessResponse(r.service.Status(app.GetRequestScope(c))))
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/lucasfloriani/go-mongo/app"
+	"github.com/lucasfloriani/go-mongo/helper"
+	"github.com/lucasfloriani/go-mongo/model"
+
+	"github.com/labstack/echo"
+)
+
+type (
+	// reportService specifies the interface for the report service needed by reportResource.
+	reportService interface {
+		Enrollments(rs app.RequestScope, filter model.ReportFilter) (model.EnrollmentReport, error)
+		Ages(rs app.RequestScope, filter model.ReportFilter, boundaries []uint) (model.AgeReport, error)
+		Phones(rs app.RequestScope, filter model.ReportFilter) (*model.PhoneReport, error)
+		Addresses(rs app.RequestScope, filter model.ReportFilter, field string) (model.AddressReport, error)
+	}
+
+	// reportResource defines the handlers for the report APIs.
+	reportResource struct {
+		service reportService
+	}
+)
+
+// ServeReportResource sets up the routing of the report endpoints.
+func ServeReportResource(e *echo.Group, service reportService) {
+	at := &reportResource{service}
+	e.GET("/reports/enrollments", at.enrollments)
+	e.GET("/reports/ages", at.ages)
+	e.GET("/reports/phones", at.phones)
+	e.GET("/reports/addresses", at.addresses)
+}
+
+// enrollments verify rest params, call service method to execute business logic
+// and return JSON or CSV data
+func (r *reportResource) enrollments(c echo.Context) error {
+	filter, err := reportFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, helper.NewRequestErrorResponse(c, err))
+	}
+	response, err := r.service.Enrollments(app.GetRequestScope(c), filter)
+	if err != nil {
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
+	}
+	return writeReport(c, "enrollments", response)
+}
+
+// ages verify rest params, call service method to execute business logic
+// and return JSON or CSV data
+func (r *reportResource) ages(c echo.Context) error {
+	filter, err := reportFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, helper.NewRequestErrorResponse(c, err))
+	}
+	boundaries, err := uintList(c.QueryParam("buckets"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, helper.NewRequestErrorResponse(c, errors.New("Limites de idade inválidos.")))
+	}
+	response, err := r.service.Ages(app.GetRequestScope(c), filter, boundaries)
+	if err != nil {
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
+	}
+	return writeReport(c, "ages", response)
+}
+
+// phones verify rest params, call service method to execute business logic
+// and return JSON or CSV data
+func (r *reportResource) phones(c echo.Context) error {
+	filter, err := reportFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, helper.NewRequestErrorResponse(c, err))
+	}
+	response, err := r.service.Phones(app.GetRequestScope(c), filter)
+	if err != nil {
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
+	}
+	return writeReport(c, "phones", response)
+}
+
+// addresses verify rest params, call service method to execute business logic
+// and return JSON or CSV data
+func (r *reportResource) addresses(c echo.Context) error {
+	filter, err := reportFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, helper.NewRequestErrorResponse(c, err))
+	}
+	field := c.QueryParam("by")
+	if field == "" {
+		field = model.AddressReportFields[0]
+	}
+	response, err := r.service.Addresses(app.GetRequestScope(c), filter, field)
+	if err != nil {
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
+	}
+	return writeReport(c, "addresses-"+field, response)
+}
+
+// reportFilter reads the filter of the reports from the course, min_age and max_age query params
+func reportFilter(c echo.Context) (model.ReportFilter, error) {
+	filter := model.ReportFilter{CourseID: c.QueryParam("course")}
+	for param, age := range map[string]*uint{"min_age": &filter.MinAge, "max_age": &filter.MaxAge} {
+		value := c.QueryParam(param)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return filter, errors.New("Idade inválida.")
+		}
+		*age = uint(n)
+	}
+	return filter, nil
+}
+
+// uintList parses a comma separated list of unsigned integers, empty when the value is empty
+func uintList(value string) ([]uint, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parts := strings.Split(value, ",")
+	list := make([]uint, len(parts))
+	for i, part := range parts {
+		n, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		list[i] = uint(n)
+	}
+	return list, nil
+}
+
+// writeReport writes the report as CSV when the format query param is "csv"
+// or the client accepts text/csv, otherwise as JSON
+func writeReport(c echo.Context, name string, report model.Table) error {
+	format := c.QueryParam("format")
+	if format != "csv" && (format != "" || !strings.Contains(c.Request().Header.Get(echo.HeaderAccept), "text/csv")) {
+		return c.JSON(http.StatusOK, helper.NewSuccessResponse(report))
+	}
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	header.Set(echo.HeaderContentDisposition, `attachment; filename="`+name+`.csv"`)
+	c.Response().WriteHeader(http.StatusOK)
+	// The status is already sent, so echo only logs the errors of the writes
+	w := csv.NewWriter(c.Response())
+	if err := w.Write(report.Header()); err != nil {
+		return err
+	}
+	return w.WriteAll(report.Records())
+}
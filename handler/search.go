@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/lucasfloriani/go-mongo/app"
+	"github.com/lucasfloriani/go-mongo/helper"
+	"github.com/lucasfloriani/go-mongo/model"
+
+	"github.com/labstack/echo"
+)
+
+type (
+	// searchService specifies the interface for the search service needed by searchResource.
+	searchService interface {
+		Count(rs app.RequestScope, query string) (int, error)
+		Query(rs app.RequestScope, query string, offset, limit int) ([]model.SearchResult, error)
+	}
+
+	// searchResource defines the handlers for the search APIs.
+	searchResource struct {
+		service searchService
+	}
+)
+
+// ServeSearchResource sets up the routing of the full-text search endpoint.
+func ServeSearchResource(e *echo.Group, service searchService) {
+	at := &searchResource{service}
+	e.GET("/search", at.query)
+}
+
+// query verify rest params, call service method to execute business logic
+// and return JSON data
+func (r *searchResource) query(c echo.Context) error {
+	rs := app.GetRequestScope(c)
+	query := c.QueryParam("q")
+	count, err := r.service.Count(rs, query)
+	if err != nil {
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
+	}
+
+	paginatedList := helper.GetPaginatedListFromRequest(c, count)
+	items, err := r.service.Query(rs, query, paginatedList.Offset(), paginatedList.Limit())
+	if err != nil {
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
+	}
+	paginatedList.Items = items
+
+	return c.JSON(http.StatusFound, helper.NewSuccessResponse(paginatedList))
+}
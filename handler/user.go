@@ -2,7 +2,9 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 
+	"github.com/lucasfloriani/go-mongo/app"
 	"github.com/lucasfloriani/go-mongo/helper"
 	"github.com/lucasfloriani/go-mongo/model"
 
@@ -12,12 +14,19 @@ import (
 type (
 	// userService specifies the interface for the user service needed by userResource.
 	userService interface {
-		Get(id string) (*model.User, error)
-		Query(offset, limit int) ([]model.User, error)
-		Count() (int, error)
-		Create(model *model.User) (*model.User, error)
-		Update(model *model.User) (*model.User, error)
-		Delete(id string) (*model.User, error)
+		Get(rs app.RequestScope, id string) (*model.User, error)
+		GetByCPF(rs app.RequestScope, cpf string) (*model.User, error)
+		GetByEmail(rs app.RequestScope, email string) (*model.User, error)
+		Query(rs app.RequestScope, offset, limit int) ([]model.User, error)
+		Count(rs app.RequestScope) (int, error)
+		ByPhone(rs app.RequestScope, number string, offset, limit int) ([]model.User, error)
+		CountByPhone(rs app.RequestScope, number string) (int, error)
+		Search(rs app.RequestScope, query string, offset, limit int) ([]model.SearchResult, error)
+		CountSearch(rs app.RequestScope, query string) (int, error)
+		Suggest(rs app.RequestScope, prefix string, limit int) ([]model.Suggestion, error)
+		Create(rs app.RequestScope, model *model.User) (*model.User, error)
+		Update(rs app.RequestScope, model *model.User) (*model.User, error)
+		Delete(rs app.RequestScope, id string) (*model.User, error)
 	}
 
 	// userResource defines the handlers for the CRUD APIs.
@@ -26,14 +35,18 @@ type (
 	}
 )
 
-// ServeUserResource sets up the routing of user endpoints and the corresponding handlers (routes)
-func ServeUserResource(e *echo.Group, service userService) {
+// ServeUserResource sets up the routing of user endpoints and the corresponding handlers (routes),
+// createMiddlewares are used only by the create endpoint
+func ServeUserResource(e *echo.Group, service userService, createMiddlewares ...echo.MiddlewareFunc) {
 	at := &userResource{service}
 	userGroup := e.Group("/user")
 	{
+		userGroup.GET("/_suggest", at.suggest)
+		userGroup.GET("/by-cpf/:cpf", at.getByCPF)
+		userGroup.GET("/by-email/:email", at.getByEmail)
 		userGroup.GET("/:userID", at.get)
 		userGroup.GET("/", at.query)
-		userGroup.POST("/", at.create)
+		userGroup.POST("/", at.create, createMiddlewares...)
 		userGroup.PUT("/:userID", at.update)
 		userGroup.DELETE("/:userID", at.delete)
 	}
@@ -42,41 +55,126 @@ func ServeUserResource(e *echo.Group, service userService) {
 // get verify rest params, call service method to execute business logic
 // and return JSON data
 func (r *userResource) get(c echo.Context) error {
-	response, err := r.service.Get(c.Param("userID"))
+	response, err := r.service.Get(app.GetRequestScope(c), c.Param("userID"))
 	if err != nil {
-		return c.JSON(http.StatusNotFound, helper.NewErrorResponse(err))
+		return c.JSON(helper.StatusCode(err, http.StatusNotFound), helper.NewRequestErrorResponse(c, err))
 	}
 	return c.JSON(http.StatusFound, helper.NewSuccessResponse(*response))
 }
 
-// query verify rest params, call service method to execute business logic
+// getByCPF verify rest params, call service method to execute business logic
+// and return JSON data
+func (r *userResource) getByCPF(c echo.Context) error {
+	response, err := r.service.GetByCPF(app.GetRequestScope(c), c.Param("cpf"))
+	if err != nil {
+		return c.JSON(helper.StatusCode(err, http.StatusNotFound), helper.NewRequestErrorResponse(c, err))
+	}
+	return c.JSON(http.StatusFound, helper.NewSuccessResponse(*response))
+}
+
+// getByEmail verify rest params, call service method to execute business logic
 // and return JSON data
+func (r *userResource) getByEmail(c echo.Context) error {
+	response, err := r.service.GetByEmail(app.GetRequestScope(c), c.Param("email"))
+	if err != nil {
+		return c.JSON(helper.StatusCode(err, http.StatusNotFound), helper.NewRequestErrorResponse(c, err))
+	}
+	return c.JSON(http.StatusFound, helper.NewSuccessResponse(*response))
+}
+
+// query verify rest params, call service method to execute business logic
+// and return JSON data. With the q param, the full-text search results are returned instead,
+// and with the phone param, the users with the phone number
 func (r *userResource) query(c echo.Context) error {
-	count, err := r.service.Count()
+	if c.QueryParam("q") != "" {
+		return r.search(c)
+	}
+	if c.QueryParam("phone") != "" {
+		return r.byPhone(c)
+	}
+
+	rs := app.GetRequestScope(c)
+	count, err := r.service.Count(rs)
+	if err != nil {
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
+	}
+
+	paginatedList := helper.GetPaginatedListFromRequest(c, count)
+	items, err := r.service.Query(rs, paginatedList.Offset(), paginatedList.Limit())
+	if err != nil {
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
+	}
+	paginatedList.Items = items
+
+	return c.JSON(http.StatusFound, helper.NewSuccessResponse(paginatedList))
+}
+
+// search verify rest params, call service method to execute business logic
+// and return JSON data
+func (r *userResource) search(c echo.Context) error {
+	rs := app.GetRequestScope(c)
+	query := c.QueryParam("q")
+	count, err := r.service.CountSearch(rs, query)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, helper.NewErrorResponse(err))
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
 	}
 
 	paginatedList := helper.GetPaginatedListFromRequest(c, count)
-	items, err := r.service.Query(paginatedList.Offset(), paginatedList.Limit())
+	items, err := r.service.Search(rs, query, paginatedList.Offset(), paginatedList.Limit())
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, helper.NewErrorResponse(err))
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
 	}
 	paginatedList.Items = items
 
 	return c.JSON(http.StatusFound, helper.NewSuccessResponse(paginatedList))
 }
 
+// byPhone verify rest params, call service method to execute business logic
+// and return JSON data
+func (r *userResource) byPhone(c echo.Context) error {
+	rs := app.GetRequestScope(c)
+	number := c.QueryParam("phone")
+	count, err := r.service.CountByPhone(rs, number)
+	if err != nil {
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
+	}
+
+	paginatedList := helper.GetPaginatedListFromRequest(c, count)
+	items, err := r.service.ByPhone(rs, number, paginatedList.Offset(), paginatedList.Limit())
+	if err != nil {
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
+	}
+	paginatedList.Items = items
+
+	return c.JSON(http.StatusFound, helper.NewSuccessResponse(paginatedList))
+}
+
+// suggest verify rest params, call service method to execute business logic
+// and return JSON data. The suggestions are cached briefly by the client, since they're
+// requested on each keystroke
+func (r *userResource) suggest(c echo.Context) error {
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	response, err := r.service.Suggest(app.GetRequestScope(c), c.QueryParam("prefix"), limit)
+	if err != nil {
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
+	}
+	if response == nil {
+		response = []model.Suggestion{}
+	}
+	c.Response().Header().Set("Cache-Control", "private, max-age=30")
+	return c.JSON(http.StatusFound, helper.NewSuccessResponse(response))
+}
+
 // create call service method to execute business logic
 // and return JSON data
 func (r *userResource) create(c echo.Context) error {
 	var model model.User
 	if err := c.Bind(&model); err != nil {
-		return c.JSON(http.StatusBadRequest, helper.NewErrorResponse(err))
+		return c.JSON(http.StatusBadRequest, helper.NewRequestErrorResponse(c, err))
 	}
-	response, err := r.service.Create(&model)
+	response, err := r.service.Create(app.GetRequestScope(c), &model)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, helper.NewErrorResponse(err))
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
 	}
 
 	return c.JSON(http.StatusCreated, helper.NewSuccessResponse(*response))
@@ -85,18 +183,26 @@ func (r *userResource) create(c echo.Context) error {
 // update verify rest params, call service method to execute business logic
 // and return JSON data
 func (r *userResource) update(c echo.Context) error {
-	model, err := r.service.Get(c.Param("userID"))
+	rs := app.GetRequestScope(c)
+	model, err := r.service.Get(rs, c.Param("userID"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, helper.NewErrorResponse(err))
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
 	}
 
+	// The ID from the URL is kept so the body can't point the update to another record,
+	// and so is the CPF when the body has it masked, as it's read
+	id, cpf := model.ID, model.CPF
 	if err := c.Bind(model); err != nil {
-		return c.JSON(http.StatusBadRequest, helper.NewErrorResponse(err))
+		return c.JSON(http.StatusBadRequest, helper.NewRequestErrorResponse(c, err))
+	}
+	model.ID = id
+	if model.CPF.IsMasked() {
+		model.CPF = cpf
 	}
 
-	response, err := r.service.Update(model)
+	response, err := r.service.Update(rs, model)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, helper.NewErrorResponse(err))
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
 	}
 
 	return c.JSON(http.StatusOK, helper.NewSuccessResponse(*response))
@@ -105,9 +211,9 @@ func (r *userResource) update(c echo.Context) error {
 // delete verify rest params, call service method to execute business logic
 // and return JSON data
 func (r *userResource) delete(c echo.Context) error {
-	response, err := r.service.Delete(c.Param("userID"))
+	response, err := r.service.Delete(app.GetRequestScope(c), c.Param("userID"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, helper.NewErrorResponse(err))
+		return c.JSON(helper.StatusCode(err, http.StatusBadRequest), helper.NewRequestErrorResponse(c, err))
 	}
 
 	return c.JSON(http.StatusOK, helper.NewSuccessResponse(*response))
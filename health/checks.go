@@ -0,0 +1,40 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/lucasfloriani/go-mongo/app"
+)
+
+var (
+	// errConfigNotLoaded is returned by Config when the configuration wasn't loaded
+	errConfigNotLoaded = errors.New("configuration not loaded")
+	// errShuttingDown is returned by Lifecycle when the application is shutting down
+	errShuttingDown = errors.New("shutting down")
+)
+
+// Config checks if the application configuration was loaded and validated.
+func Config(ctx context.Context) error {
+	if !app.Loaded() {
+		return errConfigNotLoaded
+	}
+	return nil
+}
+
+// Lifecycle checks if the application isn't shutting down, so no new requests are routed to it.
+func Lifecycle(ctx context.Context) error {
+	if app.ShuttingDown() {
+		return errShuttingDown
+	}
+	return nil
+}
+
+// Workers checks if the background workers started with app.Go are running.
+func Workers(ctx context.Context) error {
+	if stopped := app.StoppedWorkers(); len(stopped) > 0 {
+		return errors.New("stopped workers: " + strings.Join(stopped, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lucasfloriani/go-mongo/app"
+)
+
+const (
+	// StatusUp means the component is working
+	StatusUp = "up"
+	// StatusDown means the component is failing
+	StatusDown = "down"
+)
+
+// Check verifies a component, returning an error when it isn't working.
+type Check func(ctx context.Context) error
+
+// ComponentStatus is the result of the check of a component.
+type ComponentStatus struct {
+	Name    string  `json:"name"`
+	Status  string  `json:"status"`
+	Latency float64 `json:"latency_ms"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// Report is the result of the checks of every component.
+type Report struct {
+	Status     string            `json:"status"`
+	Components []ComponentStatus `json:"components,omitempty"`
+	Version    string            `json:"version,omitempty"`
+	Commit     string            `json:"commit,omitempty"`
+	BuildTime  string            `json:"build_time,omitempty"`
+	Uptime     string            `json:"uptime,omitempty"`
+}
+
+type component struct {
+	name  string
+	check Check
+}
+
+// Checker runs the checks of the components the application depends on.
+type Checker struct {
+	mu         sync.RWMutex
+	components []component
+	started    time.Time
+}
+
+// NewChecker creates a new Checker
+func NewChecker() *Checker {
+	return &Checker{started: time.Now()}
+}
+
+// Add adds the check of a component, used by the readiness and status reports.
+func (c *Checker) Add(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.components = append(c.components, component{name, check})
+}
+
+// Run runs every check concurrently, each one limited by app.Current().Health.Timeout.
+func (c *Checker) Run(ctx context.Context) Report {
+	c.mu.RLock()
+	components := c.components
+	c.mu.RUnlock()
+
+	report := Report{
+		Status:     StatusUp,
+		Components: make([]ComponentStatus, len(components)),
+		Version:    app.Version,
+		Commit:     app.Commit,
+		BuildTime:  app.BuildTime,
+		Uptime:     time.Since(c.started).Round(time.Second).String(),
+	}
+
+	var wg sync.WaitGroup
+	for i, comp := range components {
+		wg.Add(1)
+		go func(i int, comp component) {
+			defer wg.Done()
+			report.Components[i] = run(ctx, comp)
+		}(i, comp)
+	}
+	wg.Wait()
+
+	for _, status := range report.Components {
+		if status.Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+// run runs the check of the component with the configured timeout
+func run(ctx context.Context, comp component) ComponentStatus {
+	ctx, cancel := context.WithTimeout(ctx, app.Current().Health.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := make(chan error, 1)
+	go func() { err <- comp.check(ctx) }()
+
+	status := ComponentStatus{Name: comp.name, Status: StatusUp}
+	select {
+	case e := <-err:
+		if e != nil {
+			status.Status, status.Error = StatusDown, e.Error()
+		}
+	case <-ctx.Done():
+		status.Status, status.Error = StatusDown, ctx.Err().Error()
+	}
+	status.Latency = float64(time.Since(start)) / float64(time.Millisecond)
+	return status
+}
@@ -0,0 +1,15 @@
+package helper
+
+// statusCoder is implemented by errors that know the HTTP status code that represents them.
+type statusCoder interface {
+	StatusCode() int
+}
+
+// StatusCode returns the HTTP status code of the error when it declares one,
+// else returns the defaultStatus
+func StatusCode(err error, defaultStatus int) int {
+	if e, ok := err.(statusCoder); ok {
+		return e.StatusCode()
+	}
+	return defaultStatus
+}
@@ -3,13 +3,15 @@ package helper
 import (
 	"strconv"
 
+	"github.com/lucasfloriani/go-mongo/app"
+
 	"github.com/labstack/echo"
 )
 
 const (
-	// DefaultPageSize set default size of pagination
+	// DefaultPageSize set default size of pagination, when not configured
 	DefaultPageSize int = 10
-	// MaxPageSize set max size of pagination
+	// MaxPageSize set max size of pagination, when not configured
 	MaxPageSize int = 15
 )
 
@@ -60,17 +62,32 @@ func (p *PaginatedList) Limit() int {
 // GetPaginatedListFromRequest query by pagination parameters
 // and returns a list
 func GetPaginatedListFromRequest(c echo.Context, count int) *PaginatedList {
+	defaultPageSize, maxPageSize := pageSizes()
 	page := parseInt(c.QueryParam("page"), 1)
-	perPage := parseInt(c.QueryParam("per_page"), DefaultPageSize)
+	perPage := parseInt(c.QueryParam("per_page"), defaultPageSize)
 	if perPage <= 0 {
-		perPage = DefaultPageSize
+		perPage = defaultPageSize
 	}
-	if perPage > MaxPageSize {
-		perPage = MaxPageSize
+	if perPage > maxPageSize {
+		perPage = maxPageSize
 	}
 	return NewPaginatedList(page, perPage, count)
 }
 
+// pageSizes returns the page sizes configured in app.Current().Pagination,
+// using DefaultPageSize and MaxPageSize for the ones not configured
+func pageSizes() (defaultPageSize, maxPageSize int) {
+	config := app.Current().Pagination
+	defaultPageSize, maxPageSize = config.DefaultPageSize, config.MaxPageSize
+	if defaultPageSize <= 0 {
+		defaultPageSize = DefaultPageSize
+	}
+	if maxPageSize <= 0 {
+		maxPageSize = MaxPageSize
+	}
+	return
+}
+
 // parseInt check string value and try to convert to integer,
 // if ok returns converted value, else returns the defaultValue
 func parseInt(value string, defaultValue int) int {
@@ -1,14 +1,25 @@
 package helper
 
+import (
+	"github.com/labstack/echo"
+)
+
 type Response struct {
-	Error    string      `json:"error"`
-	Response interface{} `json:"response"`
+	Error     string      `json:"error"`
+	Response  interface{} `json:"response"`
+	RequestID string      `json:"request_id,omitempty"`
 }
 
 func NewErrorResponse(err error) Response {
 	return Response{Error: err.Error()}
 }
 
+// NewRequestErrorResponse creates an error response with the ID of the request,
+// so clients can report it and it can be found in the logs
+func NewRequestErrorResponse(c echo.Context, err error) Response {
+	return Response{Error: err.Error(), RequestID: c.Response().Header().Get(echo.HeaderXRequestID)}
+}
+
 func NewSuccessResponse(r interface{}) Response {
 	return Response{Response: r}
 }
@@ -0,0 +1,180 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/lucasfloriani/go-mongo/app"
+	"github.com/lucasfloriani/go-mongo/helper"
+	"github.com/lucasfloriani/go-mongo/model"
+
+	"github.com/labstack/echo"
+)
+
+const (
+	// KeyHeader is the header used by clients to identify retries of the same request
+	KeyHeader = "Idempotency-Key"
+	// ReplayedHeader is set in responses that were replayed from a previous request
+	ReplayedHeader = "Idempotent-Replayed"
+	// maxKeyLength is the max length accepted for the key
+	maxKeyLength = 255
+	// releaseTimeout limits the time to release a key after the request failed
+	releaseTimeout = 5 * time.Second
+)
+
+var (
+	// ErrKeyTooLong is returned when the key is longer than maxKeyLength
+	ErrKeyTooLong = errors.New("Idempotency-Key deve ter no máximo 255 caracteres.")
+	// ErrKeyReused is returned when the key was already used by a request with another body
+	ErrKeyReused = errors.New("Idempotency-Key já utilizada com uma requisição diferente.")
+	// ErrInProgress is returned when the first request with the key is still being processed
+	ErrInProgress = errors.New("Requisição com a mesma Idempotency-Key ainda em processamento.")
+)
+
+// store specifies the interface needed by the middleware to persist responses.
+type store interface {
+	Reserve(ctx context.Context, r *model.IdempotencyRecord) (*model.IdempotencyRecord, error)
+	Complete(ctx context.Context, r *model.IdempotencyRecord) error
+	Delete(ctx context.Context, r *model.IdempotencyRecord) error
+}
+
+// Middleware returns a middleware that stores the response of requests sent with an Idempotency-Key
+// and replays it for retries. Keys are scoped by caller, or by IP for the anonymous callers,
+// and expire after app.Config.Idempotency.TTL. Bodies over app.Config.Idempotency.MaxBodySize
+// are rejected, as they are kept in memory to fingerprint the request.
+func Middleware(s store) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(KeyHeader)
+			if key == "" {
+				return next(c)
+			}
+			if len(key) > maxKeyLength {
+				return c.JSON(http.StatusBadRequest, helper.NewRequestErrorResponse(c, ErrKeyTooLong))
+			}
+
+			maxBodySize := app.Config.Idempotency.MaxBodySize
+			body, err := ioutil.ReadAll(http.MaxBytesReader(c.Response(), c.Request().Body, maxBodySize))
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				err = fmt.Errorf("Corpo da requisição deve ter no máximo %d bytes.", maxBodySize)
+				return c.JSON(http.StatusRequestEntityTooLarge, helper.NewRequestErrorResponse(c, err))
+			}
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, helper.NewRequestErrorResponse(c, err))
+			}
+			c.Request().Body = ioutil.NopCloser(bytes.NewReader(body))
+
+			now := time.Now()
+			record := &model.IdempotencyRecord{
+				Key:         scope(c) + ":" + key,
+				Fingerprint: fingerprint(c.Request(), body),
+				CreatedAt:   now,
+			}
+			existing, err := reserve(c.Request().Context(), s, record, now)
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, helper.NewRequestErrorResponse(c, err))
+			}
+			if existing != nil {
+				return replay(c, existing, record)
+			}
+
+			// A panic of the handler releases the key, else the retries would be rejected until it expires
+			defer func() {
+				if p := recover(); p != nil {
+					release(c, s, record)
+					panic(p)
+				}
+			}()
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			record.Status = c.Response().Status
+			if record.Status >= http.StatusInternalServerError {
+				// Server errors can be retried with the same key
+				release(c, s, record)
+				return nil
+			}
+			record.ContentType = c.Response().Header().Get(echo.HeaderContentType)
+			record.Body = recorder.body.Bytes()
+			if err := s.Complete(c.Request().Context(), record); err != nil {
+				// The response was already sent, the retries will get ErrInProgress until the key expires
+				app.GetRequestScope(c).Logger().Error("idempotency record not completed", "error", err)
+			}
+			return nil
+		}
+	}
+}
+
+// scope returns the namespace of the keys of the caller: its ID, or the IP of the anonymous
+// callers, so different clients can't read each other's responses by reusing a key
+func scope(c echo.Context) string {
+	if id := app.GetRequestScope(c).Identity().ID; id != "" {
+		return "id:" + id
+	}
+	return "ip:" + c.RealIP()
+}
+
+// release deletes the reservation of the record, so the request can be retried with the same key
+func release(c echo.Context, s store, record *model.IdempotencyRecord) {
+	// The request context may be canceled already, by the client or the panic
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+	if err := s.Delete(ctx, record); err != nil {
+		app.GetRequestScope(c).Logger().Error("idempotency key not released", "error", err)
+	}
+}
+
+// reserve reserves the key of the record, replacing existing records already expired
+// but not yet removed by the TTL index
+func reserve(ctx context.Context, s store, record *model.IdempotencyRecord, now time.Time) (*model.IdempotencyRecord, error) {
+	existing, err := s.Reserve(ctx, record)
+	if err != nil || existing == nil || now.Sub(existing.CreatedAt) < app.Config.Idempotency.TTL {
+		return existing, err
+	}
+	if err := s.Delete(ctx, existing); err != nil {
+		return nil, err
+	}
+	return s.Reserve(ctx, record)
+}
+
+// replay writes the stored response when the existing record matches the request
+func replay(c echo.Context, existing, record *model.IdempotencyRecord) error {
+	if existing.Fingerprint != record.Fingerprint {
+		return c.JSON(http.StatusUnprocessableEntity, helper.NewRequestErrorResponse(c, ErrKeyReused))
+	}
+	if !existing.Completed() {
+		return c.JSON(http.StatusConflict, helper.NewRequestErrorResponse(c, ErrInProgress))
+	}
+	c.Response().Header().Set(ReplayedHeader, "true")
+	return c.Blob(existing.Status, existing.ContentType, existing.Body)
+}
+
+// fingerprint identifies the request by its method, path and body
+func fingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder keeps a copy of the body written to the response
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lucasfloriani/go-mongo/app"
+
+	"github.com/labstack/echo"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	once sync.Once
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	daoDuration  *prometheus.HistogramVec
+	daoErrors    *prometheus.CounterVec
+	daoDocuments *prometheus.HistogramVec
+)
+
+// Init creates and registers the collectors with the names and buckets of app.Config.Metrics.
+// Only the first call has effect, the collectors can't be registered twice.
+func Init() {
+	once.Do(func() {
+		config := app.Config.Metrics
+		httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Number of HTTP requests by method, route and status.",
+		}, []string{"method", "route", "status"})
+		httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: config.Namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of HTTP requests by method, route and status.",
+			Buckets:   config.HTTPBuckets,
+		}, []string{"method", "route", "status"})
+		daoDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: config.Namespace,
+			Subsystem: "dao",
+			Name:      "operation_duration_seconds",
+			Help:      "Duration of DAO operations by collection and operation.",
+			Buckets:   config.DAOBuckets,
+		}, []string{"collection", "operation"})
+		daoErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Subsystem: "dao",
+			Name:      "operation_errors_total",
+			Help:      "Number of failed DAO operations by collection and operation.",
+		}, []string{"collection", "operation"})
+		daoDocuments = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: config.Namespace,
+			Subsystem: "dao",
+			Name:      "documents_returned",
+			Help:      "Number of documents returned by DAO operations by collection and operation.",
+			Buckets:   prometheus.LinearBuckets(0, 5, 10),
+		}, []string{"collection", "operation"})
+
+		prometheus.MustRegister(httpRequests, httpDuration, daoDuration, daoErrors, daoDocuments)
+	})
+}
+
+// Handler returns the handler that exposes the registered metrics.
+func Handler() echo.HandlerFunc {
+	return echo.WrapHandler(promhttp.Handler())
+}
+
+// Middleware returns a middleware that records the count and duration of the requests,
+// labeled by the route template (e.g. /v1/user/:userID) instead of the raw path.
+func Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+
+			route := c.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+			labels := prometheus.Labels{
+				"method": c.Request().Method,
+				"route":  route,
+				"status": strconv.Itoa(c.Response().Status),
+			}
+			httpRequests.With(labels).Inc()
+			httpDuration.With(labels).Observe(time.Since(start).Seconds())
+			return nil
+		}
+	}
+}
+
+// ObserveDAO records the duration, error and number of documents returned by a DAO operation.
+// It does nothing when Init wasn't called.
+func ObserveDAO(collection, operation string, duration time.Duration, err error, documents int) {
+	if daoDuration == nil {
+		return
+	}
+	daoDuration.WithLabelValues(collection, operation).Observe(duration.Seconds())
+	if err != nil {
+		daoErrors.WithLabelValues(collection, operation).Inc()
+	}
+	daoDocuments.WithLabelValues(collection, operation).Observe(float64(documents))
+}
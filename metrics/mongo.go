@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lucasfloriani/go-mongo/app"
+
+	"github.com/mongodb/mongo-go-driver/bson"
+	"github.com/mongodb/mongo-go-driver/mongo"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// connectionStates are the fields of the serverStatus connections document exported as metrics
+var connectionStates = []string{"current", "available", "totalCreated"}
+
+// mongoCollector exports the connection stats reported by the server. They count the
+// connections of every client of the server, not only the pool of this process, which
+// the driver doesn't expose.
+type mongoCollector struct {
+	db          *mongo.Database
+	connections *prometheus.Desc
+	up          *prometheus.Desc
+}
+
+var (
+	mongoMu sync.Mutex
+	// registeredMongo is the collector registered by the last RegisterMongo
+	registeredMongo *mongoCollector
+)
+
+// RegisterMongo registers the collector of the connection stats of the given database, replacing
+// the one of a previous call, e.g. when the application is set up again by the e2e harness.
+func RegisterMongo(db *mongo.Database) error {
+	mongoMu.Lock()
+	defer mongoMu.Unlock()
+	if registeredMongo != nil {
+		prometheus.Unregister(registeredMongo)
+		registeredMongo = nil
+	}
+
+	namespace := app.Config.Metrics.Namespace
+	collector := &mongoCollector{
+		db: db,
+		connections: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "mongo", "server_connections"),
+			"Number of connections to the Mongo server from all its clients by state, as reported by serverStatus.",
+			[]string{"state"}, nil,
+		),
+		up: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "mongo", "up"),
+			"Whether the last serverStatus command succeeded.",
+			nil, nil,
+		),
+	}
+	if err := prometheus.Register(collector); err != nil {
+		return err
+	}
+	registeredMongo = collector
+	return nil
+}
+
+func (c *mongoCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.connections
+	ch <- c.up
+}
+
+func (c *mongoCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	status, err := c.db.RunCommand(ctx, bson.NewDocument(bson.EC.Int32("serverStatus", 1)))
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, 0)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, 1)
+
+	for _, state := range connectionStates {
+		elem, err := status.Lookup("connections", state)
+		if err != nil {
+			continue
+		}
+		var value float64
+		switch elem.Value().Type() {
+		case bson.TypeInt32:
+			value = float64(elem.Value().Int32())
+		case bson.TypeInt64:
+			value = float64(elem.Value().Int64())
+		default:
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.connections, prometheus.GaugeValue, value, state)
+	}
+}
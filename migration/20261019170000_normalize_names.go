@@ -0,0 +1,84 @@
+package migration
+
+import (
+	"context"
+
+	"github.com/lucasfloriani/go-mongo/search"
+
+	"github.com/mongodb/mongo-go-driver/bson"
+	"github.com/mongodb/mongo-go-driver/bson/objectid"
+	"github.com/mongodb/mongo-go-driver/mongo"
+)
+
+// normalizedCollections have the name_normalized field used by the suggestions
+var normalizedCollections = []string{"user", "course"}
+
+func init() {
+	Register(Migration{
+		Version: 20261019170000,
+		Name:    "normalize names",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			for _, collection := range normalizedCollections {
+				if err := normalizeNames(ctx, db.Collection(collection)); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			for _, collection := range normalizedCollections {
+				_, err := db.Collection(collection).UpdateMany(
+					ctx,
+					bson.NewDocument(),
+					bson.NewDocument(
+						bson.EC.SubDocumentFromElements("$unset",
+							bson.EC.String("name_normalized", ""),
+						),
+					),
+				)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
+
+// normalizeNames fills the name_normalized field of the documents created before it existed
+func normalizeNames(ctx context.Context, coll *mongo.Collection) error {
+	cur, err := coll.Find(ctx, bson.NewDocument(
+		bson.EC.SubDocumentFromElements("name_normalized",
+			bson.EC.Boolean("$exists", false),
+		),
+	))
+	if err != nil {
+		return err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var elem struct {
+			ID   objectid.ObjectID `bson:"_id"`
+			Name string            `bson:"name"`
+		}
+		if err := cur.Decode(&elem); err != nil {
+			return err
+		}
+		_, err := coll.UpdateOne(
+			ctx,
+			bson.NewDocument(
+				bson.EC.ObjectID("_id", elem.ID),
+			),
+			bson.NewDocument(
+				bson.EC.SubDocumentFromElements("$set",
+					bson.EC.String("name_normalized", search.Normalize(elem.Name)),
+				),
+			),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return cur.Err()
+}
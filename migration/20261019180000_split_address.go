@@ -0,0 +1,87 @@
+package migration
+
+import (
+	"context"
+
+	"github.com/lucasfloriani/go-mongo/address"
+	"github.com/lucasfloriani/go-mongo/model"
+
+	"github.com/mongodb/mongo-go-driver/bson"
+	"github.com/mongodb/mongo-go-driver/bson/objectid"
+	"github.com/mongodb/mongo-go-driver/mongo"
+)
+
+func init() {
+	Register(Migration{
+		Version: 20261019180000,
+		Name:    "split address",
+		// Up parses the free-text address.name of the users in the structured address,
+		// the parts it can't find are left empty and must be fixed on the next update of the user
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return rewriteAddresses(ctx, db.Collection("user"), true, func(elem *addressDocument) *bson.Element {
+				a := address.Parse(elem.Address.Name)
+				return bson.EC.SubDocumentFromElements("address",
+					bson.EC.String("street", a.Street),
+					bson.EC.String("number", a.Number),
+					bson.EC.String("complement", a.Complement),
+					bson.EC.String("neighborhood", a.Neighborhood),
+					bson.EC.String("city", a.City),
+					bson.EC.String("state", a.State),
+					bson.EC.String("cep", a.CEP),
+					bson.EC.String("country", a.Country),
+				)
+			})
+		},
+		// Down joins the structured address back in address.name
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return rewriteAddresses(ctx, db.Collection("user"), false, func(elem *addressDocument) *bson.Element {
+				return bson.EC.SubDocumentFromElements("address",
+					bson.EC.String("name", elem.Address.String()),
+				)
+			})
+		},
+	})
+}
+
+// addressDocument has the address of a user in both formats
+type addressDocument struct {
+	ID      objectid.ObjectID `bson:"_id"`
+	Address struct {
+		model.Address `bson:",inline"`
+		Name          string `bson:"name"`
+	} `bson:"address"`
+}
+
+// rewriteAddresses replaces the address of the users with address.name, or without it when named is false,
+// by the one returned by rewrite
+func rewriteAddresses(ctx context.Context, coll *mongo.Collection, named bool, rewrite func(*addressDocument) *bson.Element) error {
+	cur, err := coll.Find(ctx, bson.NewDocument(
+		bson.EC.SubDocumentFromElements("address.name",
+			bson.EC.Boolean("$exists", named),
+		),
+	))
+	if err != nil {
+		return err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var elem addressDocument
+		if err := cur.Decode(&elem); err != nil {
+			return err
+		}
+		_, err := coll.UpdateOne(
+			ctx,
+			bson.NewDocument(
+				bson.EC.ObjectID("_id", elem.ID),
+			),
+			bson.NewDocument(
+				bson.EC.SubDocumentFromElements("$set", rewrite(&elem)),
+			),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return cur.Err()
+}
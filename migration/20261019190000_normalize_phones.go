@@ -0,0 +1,98 @@
+package migration
+
+import (
+	"context"
+	"strings"
+
+	"github.com/lucasfloriani/go-mongo/app"
+	"github.com/lucasfloriani/go-mongo/model"
+	"github.com/lucasfloriani/go-mongo/phone"
+
+	"github.com/mongodb/mongo-go-driver/bson"
+	"github.com/mongodb/mongo-go-driver/bson/objectid"
+	"github.com/mongodb/mongo-go-driver/mongo"
+)
+
+func init() {
+	Register(Migration{
+		Version: 20261019190000,
+		Name:    "normalize phones",
+		// Up converts the phones of the users to the E.164 format, as brazilian numbers unless they
+		// have the calling code, infers their type and marks the first one as primary when none is.
+		// The numbers that can't be converted, like the ones without the area code, are kept as
+		// written and logged, to be fixed by the users.
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			logger := app.Logger("migration")
+			coll := db.Collection("user")
+			cur, err := coll.Find(ctx, bson.NewDocument(
+				bson.EC.SubDocumentFromElements("phones.country",
+					bson.EC.Boolean("$exists", false),
+				),
+			))
+			if err != nil {
+				return err
+			}
+			defer cur.Close(ctx)
+
+			for cur.Next(ctx) {
+				var elem struct {
+					ID     objectid.ObjectID `bson:"_id"`
+					Phones []model.Phone     `bson:"phones"`
+				}
+				if err := cur.Decode(&elem); err != nil {
+					return err
+				}
+				primary := false
+				for _, p := range elem.Phones {
+					primary = primary || p.Primary
+				}
+				phones := make([]*bson.Value, len(elem.Phones))
+				for i, p := range elem.Phones {
+					written := strings.TrimSpace(p.Number)
+					phone.Normalize(&p)
+					if err := p.Validate(); err != nil {
+						p.Number = written
+						logger.Warn("phone not normalized", "user", elem.ID.Hex(), "phone", i, "error", err)
+					}
+					p.Primary = p.Primary || !primary && i == 0
+					phones[i] = bson.VC.DocumentFromElements(
+						bson.EC.String("number", p.Number),
+						bson.EC.String("type", p.Type),
+						bson.EC.Boolean("primary", p.Primary),
+						bson.EC.String("country", p.Country),
+					)
+				}
+				_, err := coll.UpdateOne(
+					ctx,
+					bson.NewDocument(
+						bson.EC.ObjectID("_id", elem.ID),
+					),
+					bson.NewDocument(
+						bson.EC.SubDocumentFromElements("$set",
+							bson.EC.ArrayFromElements("phones", phones...),
+						),
+					),
+				)
+				if err != nil {
+					return err
+				}
+			}
+			return cur.Err()
+		},
+		// Down removes the type, primary and country of the phones, the numbers are kept in the E.164 format
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("user").UpdateMany(
+				ctx,
+				bson.NewDocument(),
+				bson.NewDocument(
+					bson.EC.SubDocumentFromElements("$unset",
+						bson.EC.String("phones.$[].type", ""),
+						bson.EC.String("phones.$[].primary", ""),
+						bson.EC.String("phones.$[].country", ""),
+					),
+				),
+			)
+			return err
+		},
+	})
+}
@@ -0,0 +1,64 @@
+package migration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// nameRegex matches the characters that aren't allowed in the file name of a migration
+var nameRegex = regexp.MustCompile(`[^a-z0-9]+`)
+
+// migrationTemplate is the source of a new migration
+var migrationTemplate = template.Must(template.New("migration").Parse(`package migration
+
+import (
+	"context"
+
+	"github.com/mongodb/mongo-go-driver/mongo"
+)
+
+func init() {
+	Register(Migration{
+		Version: {{.Version}},
+		Name:    {{printf "%q" .Name}},
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return nil
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return nil
+		},
+	})
+}
+`))
+
+// Create writes the source of a new migration in dir, versioned by the current time,
+// returning the path of the file. The binary must be rebuilt to register it.
+func Create(dir, name string) (string, error) {
+	slug := strings.Trim(nameRegex.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if slug == "" {
+		return "", fmt.Errorf("Invalid migration name %q", name)
+	}
+
+	version, _ := strconv.ParseInt(time.Now().UTC().Format("20060102150405"), 10, 64)
+	path := filepath.Join(dir, fmt.Sprintf("%d_%s.go", version, slug))
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	data := struct {
+		Version int64
+		Name    string
+	}{version, strings.Replace(slug, "_", " ", -1)}
+	if err := migrationTemplate.Execute(file, data); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,43 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/mongodb/mongo-go-driver/mongo"
+)
+
+// Migration transforms the documents of the database from one version of the models to the next.
+// Migrations are declared in this package, one per file, and registered in the file init function.
+type Migration struct {
+	// Version orders the migrations, the timestamp of its creation (e.g. 20261019143000)
+	Version int64
+	// Name describes the migration
+	Name string
+	// Up applies the migration
+	Up func(ctx context.Context, db *mongo.Database) error
+	// Down reverts the migration
+	Down func(ctx context.Context, db *mongo.Database) error
+}
+
+// registered are the migrations declared in this package
+var registered = map[int64]Migration{}
+
+// Register registers a migration, it panics when the version is already registered.
+func Register(m Migration) {
+	if existing, ok := registered[m.Version]; ok {
+		panic(fmt.Sprintf("migration %d already registered by %q", m.Version, existing.Name))
+	}
+	registered[m.Version] = m
+}
+
+// migrations returns the registered migrations ordered by version
+func migrations() []Migration {
+	list := make([]Migration, 0, len(registered))
+	for _, m := range registered {
+		list = append(list, m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list
+}
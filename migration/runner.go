@@ -0,0 +1,300 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/lucasfloriani/go-mongo/app"
+	mongodb "github.com/lucasfloriani/go-mongo/db"
+
+	"github.com/mongodb/mongo-go-driver/bson"
+	"github.com/mongodb/mongo-go-driver/mongo"
+)
+
+const (
+	// lockID is the ID of the document that locks the migrations
+	lockID = "lock"
+	// lockTimeout is when a lock is considered abandoned by a runner that crashed
+	lockTimeout = 15 * time.Minute
+	// lockPoll is the interval UpWhenUnlocked check if the lock was released
+	lockPoll = 5 * time.Second
+)
+
+var (
+	// ErrLocked is returned when another runner is applying migrations
+	ErrLocked = errors.New("Migrations locked by another runner")
+	// ErrLockLost is returned when the lock was taken over by another runner while migrating
+	ErrLockLost = errors.New("Migrations lock taken over by another runner")
+)
+
+// Status is the state of a registered migration.
+type Status struct {
+	Version   int64
+	Name      string
+	AppliedAt time.Time
+}
+
+// Applied check if the migration was applied
+func (s Status) Applied() bool {
+	return !s.AppliedAt.IsZero()
+}
+
+// Runner applies and reverts the migrations, recording the applied versions in the
+// migrations collection.
+type Runner struct {
+	db     *mongo.Database
+	owner  string
+	logger *slog.Logger
+	// lockTimeout is when the lock is considered abandoned, it's renewed three times within it
+	lockTimeout time.Duration
+	// lockPoll is the interval UpWhenUnlocked check if the lock was released
+	lockPoll time.Duration
+}
+
+// NewRunner creates a new Runner for the given database
+func NewRunner(db *mongo.Database) *Runner {
+	host, _ := os.Hostname()
+	return &Runner{
+		db:          db,
+		owner:       fmt.Sprintf("%s:%d", host, os.Getpid()),
+		logger:      app.Logger("migration"),
+		lockTimeout: lockTimeout,
+		lockPoll:    lockPoll,
+	}
+}
+
+// Status returns the state of every registered migration.
+func (r *Runner) Status(ctx context.Context) ([]Status, error) {
+	applied, err := r.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var list []Status
+	for _, m := range migrations() {
+		list = append(list, Status{Version: m.Version, Name: m.Name, AppliedAt: applied[m.Version]})
+	}
+	return list, nil
+}
+
+// Up applies the pending migrations in order, stopping on the first failure.
+func (r *Runner) Up(ctx context.Context) error {
+	return r.locked(ctx, func(ctx context.Context) error {
+		applied, err := r.applied(ctx)
+		if err != nil {
+			return err
+		}
+		for _, m := range migrations() {
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+			if err := m.Up(ctx, r.db); err != nil {
+				return fmt.Errorf("Failed to apply the migration %d %s: %s", m.Version, m.Name, err)
+			}
+			if err := r.record(ctx, m); err != nil {
+				return err
+			}
+			r.logger.Info("migration applied", "version", m.Version, "migration", m.Name)
+		}
+		return nil
+	})
+}
+
+// UpWhenUnlocked applies the pending migrations like Up, but waits while another runner holds
+// the lock instead of returning ErrLocked, so replicas starting together with automatic
+// migrations wait for the one applying them.
+func (r *Runner) UpWhenUnlocked(ctx context.Context) error {
+	for {
+		err := r.Up(ctx)
+		if err != ErrLocked {
+			return err
+		}
+		r.logger.Info("waiting for the migrations applied by another runner")
+		select {
+		case <-time.After(r.lockPoll):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Down reverts the last applied migration.
+func (r *Runner) Down(ctx context.Context) error {
+	return r.locked(ctx, func(ctx context.Context) error {
+		applied, err := r.applied(ctx)
+		if err != nil {
+			return err
+		}
+		list := migrations()
+		for i := len(list) - 1; i >= 0; i-- {
+			m := list[i]
+			if _, ok := applied[m.Version]; !ok {
+				continue
+			}
+			if m.Down == nil {
+				return fmt.Errorf("The migration %d %s can't be reverted", m.Version, m.Name)
+			}
+			if err := m.Down(ctx, r.db); err != nil {
+				return fmt.Errorf("Failed to revert the migration %d %s: %s", m.Version, m.Name, err)
+			}
+			if err := r.forget(ctx, m); err != nil {
+				return err
+			}
+			r.logger.Info("migration reverted", "version", m.Version, "migration", m.Name)
+			return nil
+		}
+		return nil
+	})
+}
+
+// applied returns when each applied migration was applied
+func (r *Runner) applied(ctx context.Context) (map[int64]time.Time, error) {
+	cur, err := r.db.Collection("migrations").Find(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	applied := map[int64]time.Time{}
+	for cur.Next(ctx) {
+		var elem struct {
+			Version   int64     `bson:"_id"`
+			AppliedAt time.Time `bson:"applied_at"`
+		}
+		if err := cur.Decode(&elem); err != nil {
+			return nil, err
+		}
+		applied[elem.Version] = elem.AppliedAt
+	}
+	return applied, cur.Err()
+}
+
+// record saves the migration as applied
+func (r *Runner) record(ctx context.Context, m Migration) error {
+	_, err := r.db.Collection("migrations").InsertOne(
+		ctx,
+		bson.NewDocument(
+			bson.EC.Int64("_id", m.Version),
+			bson.EC.String("name", m.Name),
+			bson.EC.Time("applied_at", time.Now()),
+		),
+	)
+	return err
+}
+
+// forget removes the migration from the applied ones
+func (r *Runner) forget(ctx context.Context, m Migration) error {
+	_, err := r.db.Collection("migrations").DeleteOne(
+		ctx,
+		bson.NewDocument(
+			bson.EC.Int64("_id", m.Version),
+		),
+	)
+	return err
+}
+
+// locked runs fn holding the lock of the migrations, so concurrent runners (e.g. replicas
+// starting together with automatic migrations) don't apply the same migration twice. The lock
+// is renewed while fn runs, and the context of fn is cancelled if it's taken over anyway
+func (r *Runner) locked(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := r.lock(ctx); err != nil {
+		return err
+	}
+	defer r.unlock(ctx)
+
+	renewCtx, cancel := context.WithCancel(ctx)
+	renewed := make(chan error, 1)
+	go func() {
+		renewed <- r.renew(renewCtx, cancel)
+	}()
+	err := fn(renewCtx)
+	cancel()
+	if lost := <-renewed; lost != nil {
+		return lost
+	}
+	return err
+}
+
+// renew refreshes the lock until ctx is done. It returns ErrLockLost, cancelling ctx, when the
+// lock isn't held by the runner anymore. Failed renewals are retried, the lock only expires
+// after three of them
+func (r *Runner) renew(ctx context.Context, cancel context.CancelFunc) error {
+	ticker := time.NewTicker(r.lockTimeout / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+		res, err := r.db.Collection("migrations_lock").UpdateOne(
+			ctx,
+			bson.NewDocument(
+				bson.EC.String("_id", lockID),
+				bson.EC.String("owner", r.owner),
+			),
+			bson.NewDocument(
+				bson.EC.SubDocumentFromElements("$set",
+					bson.EC.Time("locked_at", time.Now()),
+				),
+			),
+		)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return nil
+		case err != nil:
+			r.logger.Warn("failed to renew the migrations lock", "error", err)
+		case res.MatchedCount == 0:
+			cancel()
+			return ErrLockLost
+		}
+	}
+}
+
+// lock acquires the lock, taking over locks not renewed within the lock timeout
+func (r *Runner) lock(ctx context.Context) error {
+	locks := r.db.Collection("migrations_lock")
+	_, err := locks.InsertOne(
+		ctx,
+		bson.NewDocument(
+			bson.EC.String("_id", lockID),
+			bson.EC.String("owner", r.owner),
+			bson.EC.Time("locked_at", time.Now()),
+		),
+	)
+	if err == nil || !mongodb.IsDuplicateKey(err) {
+		return err
+	}
+
+	res, err := locks.DeleteOne(
+		ctx,
+		bson.NewDocument(
+			bson.EC.String("_id", lockID),
+			bson.EC.SubDocumentFromElements("locked_at",
+				bson.EC.Time("$lt", time.Now().Add(-r.lockTimeout)),
+			),
+		),
+	)
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrLocked
+	}
+	return r.lock(ctx)
+}
+
+// unlock releases the lock held by the runner
+func (r *Runner) unlock(ctx context.Context) error {
+	_, err := r.db.Collection("migrations_lock").DeleteOne(
+		ctx,
+		bson.NewDocument(
+			bson.EC.String("_id", lockID),
+			bson.EC.String("owner", r.owner),
+		),
+	)
+	return err
+}
@@ -0,0 +1,203 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mongodb/mongo-go-driver/bson/objectid"
+	"github.com/mongodb/mongo-go-driver/mongo"
+)
+
+// testDatabase returns an empty database in the Mongo of the GOMONGO_E2E_MONGO environment
+// variable, dropped when the test finishes. The test is skipped when it isn't set.
+func testDatabase(t *testing.T) *mongo.Database {
+	t.Helper()
+	connection := os.Getenv("GOMONGO_E2E_MONGO")
+	if connection == "" {
+		t.Skip("GOMONGO_E2E_MONGO not set")
+	}
+	ctx := context.Background()
+	client, err := mongo.NewClient(connection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Connect(ctx); err != nil {
+		t.Fatal(err)
+	}
+	database := client.Database("migration_" + objectid.New().Hex())
+	t.Cleanup(func() {
+		database.Drop(ctx)
+		client.Disconnect(ctx)
+	})
+	return database
+}
+
+// withMigrations replaces the registered migrations while the test runs
+func withMigrations(t *testing.T, list ...Migration) {
+	saved := registered
+	registered = map[int64]Migration{}
+	for _, m := range list {
+		Register(m)
+	}
+	t.Cleanup(func() { registered = saved })
+}
+
+// newRunner creates a runner with the given owner, so a test can run concurrent runners
+func newRunner(db *mongo.Database, owner string) *Runner {
+	r := NewRunner(db)
+	r.owner = owner
+	r.lockPoll = 10 * time.Millisecond
+	return r
+}
+
+func TestRegister(t *testing.T) {
+	withMigrations(t,
+		Migration{Version: 3, Name: "third"},
+		Migration{Version: 1, Name: "first"},
+		Migration{Version: 2, Name: "second"},
+	)
+	var names []string
+	for _, m := range migrations() {
+		names = append(names, m.Name)
+	}
+	if got := strings.Join(names, " "); got != "first second third" {
+		t.Errorf("migrations() = %s, want first second third", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Register with a registered version didn't panic")
+		}
+	}()
+	Register(Migration{Version: 2, Name: "duplicate"})
+}
+
+func TestRunnerUpDown(t *testing.T) {
+	db := testDatabase(t)
+	ctx := context.Background()
+	var applied []int64
+	up := func(version int64) func(context.Context, *mongo.Database) error {
+		return func(context.Context, *mongo.Database) error {
+			applied = append(applied, version)
+			return nil
+		}
+	}
+	withMigrations(t,
+		Migration{Version: 1, Name: "first", Up: up(1), Down: func(context.Context, *mongo.Database) error { return nil }},
+		Migration{Version: 2, Name: "second", Up: up(2)},
+		Migration{Version: 3, Name: "failing", Up: func(context.Context, *mongo.Database) error { return errors.New("failed") }},
+	)
+	r := newRunner(db, "test")
+
+	if err := r.Up(ctx); err == nil {
+		t.Fatal("Up didn't return the error of the failing migration")
+	}
+	if err := r.Up(ctx); err == nil {
+		t.Fatal("Up didn't return the error of the failing migration")
+	}
+	if len(applied) != 2 || applied[0] != 1 || applied[1] != 2 {
+		t.Errorf("applied %v, want [1 2] once", applied)
+	}
+	status, err := r.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status[0].Applied() || !status[1].Applied() || status[2].Applied() {
+		t.Errorf("Status() = %+v, want the first two applied", status)
+	}
+
+	if err := r.Down(ctx); err == nil {
+		t.Error("Down reverted a migration without Down")
+	}
+}
+
+func TestRunnerLock(t *testing.T) {
+	db := testDatabase(t)
+	ctx := context.Background()
+	first, second := newRunner(db, "first"), newRunner(db, "second")
+
+	if err := first.lock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.lock(ctx); err != ErrLocked {
+		t.Fatalf("lock() of another runner = %v, want ErrLocked", err)
+	}
+	if err := second.unlock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.lock(ctx); err != ErrLocked {
+		t.Fatalf("unlock() of another runner released the lock, lock() = %v", err)
+	}
+	if err := first.unlock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.lock(ctx); err != nil {
+		t.Fatalf("lock() after unlock() = %v", err)
+	}
+}
+
+func TestRunnerLockTakeover(t *testing.T) {
+	db := testDatabase(t)
+	ctx := context.Background()
+	crashed, second := newRunner(db, "crashed"), newRunner(db, "second")
+	crashed.lockTimeout, second.lockTimeout = 50*time.Millisecond, 50*time.Millisecond
+
+	if err := crashed.lock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if err := second.lock(ctx); err != nil {
+		t.Fatalf("lock() of an abandoned lock = %v", err)
+	}
+}
+
+func TestRunnerLockRenewal(t *testing.T) {
+	db := testDatabase(t)
+	ctx := context.Background()
+	first, second := newRunner(db, "first"), newRunner(db, "second")
+	first.lockTimeout, second.lockTimeout = 150*time.Millisecond, 150*time.Millisecond
+
+	withMigrations(t, Migration{Version: 1, Name: "slow", Up: func(ctx context.Context, _ *mongo.Database) error {
+		// Outlives the lock timeout, the renewals keep the other runner out
+		time.Sleep(400 * time.Millisecond)
+		if err := second.lock(ctx); err != ErrLocked {
+			return errors.New("lock taken over while migrating")
+		}
+		return nil
+	}})
+	if err := first.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunnerUpWhenUnlocked(t *testing.T) {
+	db := testDatabase(t)
+	ctx := context.Background()
+	holder, waiting := newRunner(db, "holder"), newRunner(db, "waiting")
+	applied := 0
+	withMigrations(t, Migration{Version: 1, Name: "first", Up: func(context.Context, *mongo.Database) error {
+		applied++
+		return nil
+	}})
+
+	if err := holder.lock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := waiting.Up(ctx); err != ErrLocked {
+		t.Fatalf("Up() while locked = %v, want ErrLocked", err)
+	}
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		holder.unlock(ctx)
+	}()
+	if err := waiting.UpWhenUnlocked(ctx); err != nil {
+		t.Fatalf("UpWhenUnlocked() = %v", err)
+	}
+	if applied != 1 {
+		t.Errorf("applied %d times, want 1", applied)
+	}
+}
@@ -1,17 +1,87 @@
 package model
 
 import (
+	"strings"
+
 	"github.com/go-ozzo/ozzo-validation"
+	"github.com/lucasfloriani/brazilian-ozzo-validation"
 )
 
-// Address represents an address record.
+// Address represents a brazilian address record.
 type Address struct {
-	Name string `json:"name"`
+	Street       string `json:"street"`
+	Number       string `json:"number"`
+	Complement   string `json:"complement"`
+	Neighborhood string `json:"neighborhood"`
+	City         string `json:"city"`
+	// State is the UF of the state, e.g. "SP"
+	State string `json:"state"`
+	// CEP is formatted as 00000-000
+	CEP     string `json:"cep"`
+	Country string `json:"country"`
 }
 
 // Validate validates the Address fields
 func (a *Address) Validate() error {
 	return validation.ValidateStruct(a,
-		validation.Field(&a.Name, validation.Required.Error("Endereço vazio.")),
+		validation.Field(
+			&a.Street,
+			validation.Required.Error("Logradouro vazio."),
+			validation.Length(0, 100).Error("Logradouro deve ter até 100 caracteres."),
+		),
+		validation.Field(
+			&a.Number,
+			validation.Required.Error("Número do endereço vazio."),
+			validation.Length(0, 10).Error("Número do endereço deve ter até 10 caracteres."),
+		),
+		validation.Field(
+			&a.Complement,
+			validation.Length(0, 50).Error("Complemento deve ter até 50 caracteres."),
+		),
+		validation.Field(
+			&a.Neighborhood,
+			validation.Length(0, 50).Error("Bairro deve ter até 50 caracteres."),
+		),
+		validation.Field(
+			&a.City,
+			validation.Required.Error("Cidade vazia."),
+			validation.Length(0, 50).Error("Cidade deve ter até 50 caracteres."),
+		),
+		validation.Field(
+			&a.State,
+			validation.Required.Error("Estado vazio."),
+			isbr.UF.Error("Estado inválido, use a sigla da UF."),
+		),
+		validation.Field(
+			&a.CEP,
+			validation.Required.Error("CEP vazio."),
+			isbr.CEP.Error("Formato do CEP é inválido."),
+		),
+		validation.Field(&a.Country, validation.Required.Error("País vazio.")),
 	)
 }
+
+// String formats the address in a single line,
+// e.g. "Rua das Flores, 100 - Apto 12 - Centro, São Paulo - SP, 01001-000"
+func (a Address) String() string {
+	var b strings.Builder
+	b.WriteString(a.Street)
+	if a.Number != "" {
+		b.WriteString(", " + a.Number)
+	}
+	for _, s := range []string{a.Complement, a.Neighborhood} {
+		if s != "" {
+			b.WriteString(" - " + s)
+		}
+	}
+	if a.City != "" {
+		b.WriteString(", " + a.City)
+	}
+	if a.State != "" {
+		b.WriteString(" - " + a.State)
+	}
+	if a.CEP != "" {
+		b.WriteString(", " + a.CEP)
+	}
+	return strings.TrimPrefix(b.String(), ", ")
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+
+	"github.com/go-ozzo/ozzo-validation"
+	"github.com/mongodb/mongo-go-driver/bson/objectid"
+)
+
+// APIKey represents an API key record used by machine-to-machine clients.
+// Only the hash of the key is stored, the plaintext Key is filled just when the key is generated.
+type APIKey struct {
+	ID         objectid.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Name       string            `json:"name,omitempty"`
+	Owner      string            `json:"owner,omitempty"`
+	Prefix     string            `json:"prefix,omitempty"`
+	Hash       string            `json:"-"`
+	Scopes     []string          `json:"scopes"`
+	ExpiresAt  time.Time         `json:"expires_at,omitempty" bson:"expires_at"`
+	LastUsedAt time.Time         `json:"last_used_at,omitempty" bson:"last_used_at"`
+	CreatedAt  time.Time         `json:"created_at,omitempty" bson:"created_at"`
+	Revoked    bool              `json:"revoked"`
+	Key        string            `json:"key,omitempty" bson:"-"`
+}
+
+// NewAPIKey creates a new APIKey
+func NewAPIKey() *APIKey {
+	return &APIKey{}
+}
+
+// Expired check if the key has an expiration date that already passed
+func (k APIKey) Expired(now time.Time) bool {
+	return !k.ExpiresAt.IsZero() && now.After(k.ExpiresAt)
+}
+
+// Validate validates the APIKey fields
+func (k APIKey) Validate() error {
+	return validation.ValidateStruct(&k,
+		validation.Field(
+			&k.Name,
+			validation.Required.Error("Nome da chave vazio."),
+			validation.Length(3, 50).Error("Nome da chave deve estar entre 3 à 50 caracteres"),
+		),
+		validation.Field(
+			&k.Scopes,
+			validation.Required.Error("É necessário pelo menos um escopo."),
+		),
+	)
+}
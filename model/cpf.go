@@ -0,0 +1,52 @@
+package model
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strings"
+)
+
+// CPF is the brazilian taxpayer number of a person, stored as its 11 digits.
+// It's masked in the JSON and the logs, keeping only the middle digits.
+type CPF string
+
+// NormalizeCPF returns the digits of a CPF formatted as 000.000.000-00 or not,
+// other values are returned trimmed, to fail the validation.
+func NormalizeCPF(cpf string) CPF {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		if r == '.' || r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, cpf)
+	if len(digits) != 11 {
+		return CPF(strings.TrimSpace(cpf))
+	}
+	return CPF(digits)
+}
+
+// Masked formats the CPF hiding the first three and the check digits, e.g. ***.456.789-**
+func (c CPF) Masked() string {
+	if len(c) != 11 {
+		return strings.Repeat("*", len(c))
+	}
+	return "***." + string(c[3:6]) + "." + string(c[6:9]) + "-**"
+}
+
+// IsMasked check if the CPF is masked, as sent back by the clients that read it
+func (c CPF) IsMasked() bool {
+	return strings.Contains(string(c), "*")
+}
+
+// MarshalJSON writes the masked CPF
+func (c CPF) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.Masked())
+}
+
+// LogValue logs the masked CPF
+func (c CPF) LogValue() slog.Value {
+	return slog.StringValue(c.Masked())
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+)
+
+// IdempotencyRecord represents the stored response of a request sent with an Idempotency-Key.
+// A record without Status is still being processed by the first request.
+type IdempotencyRecord struct {
+	Key         string    `json:"key" bson:"_id"`
+	Fingerprint string    `json:"fingerprint"`
+	Status      int       `json:"status"`
+	ContentType string    `json:"content_type" bson:"content_type"`
+	Body        []byte    `json:"body"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+}
+
+// Completed check if the response of the first request was already stored
+func (r IdempotencyRecord) Completed() bool {
+	return r.Status != 0
+}
@@ -1,22 +1,68 @@
 package model
 
 import (
+	"regexp"
+
 	"github.com/go-ozzo/ozzo-validation"
-	"github.com/lucasfloriani/brazilian-ozzo-validation"
+	"github.com/go-ozzo/ozzo-validation/is"
+)
+
+// The types of phone
+const (
+	PhoneMobile   = "mobile"
+	PhoneLandline = "landline"
+	PhoneWhatsApp = "whatsapp"
+)
+
+var (
+	// E164 matches the numbers in the E.164 format, e.g. +5541999990000
+	E164 = regexp.MustCompile(`^\+[1-9]\d{7,14}$`)
+	// brazilianMobile and brazilianLandline match the brazilian numbers in the E.164 format,
+	// with the area code (DDD) and the 9 digits of the mobiles or the 8 of the landlines
+	brazilianMobile   = regexp.MustCompile(`^\+55[1-9]{2}9\d{8}$`)
+	brazilianLandline = regexp.MustCompile(`^\+55[1-9]{2}[2-5]\d{7}$`)
+	// brazilianWhatsApp matches both, since WhatsApp Business accepts landlines
+	brazilianWhatsApp = regexp.MustCompile(`^\+55[1-9]{2}(9\d{8}|[2-5]\d{7})$`)
 )
 
 // Phone represents an phone record.
 type Phone struct {
+	// Number is in the E.164 format, e.g. +5541999990000
 	Number string `json:"number"`
+	// Type is one of PhoneMobile, PhoneLandline or PhoneWhatsApp
+	Type    string `json:"type"`
+	Primary bool   `json:"primary"`
+	// Country is the ISO 3166-1 alpha-2 code of the country of the number, e.g. "BR"
+	Country string `json:"country"`
 }
 
 // Validate validates the Phone fields
 func (p *Phone) Validate() error {
+	number := []validation.Rule{
+		validation.Required.Error("Número de telefone não fornecido."),
+		validation.Match(E164).Error("Formato do número é inválido."),
+	}
+	if p.Country == "BR" {
+		switch p.Type {
+		case PhoneLandline:
+			number = append(number, validation.Match(brazilianLandline).Error("Número de telefone fixo inválido."))
+		case PhoneWhatsApp:
+			number = append(number, validation.Match(brazilianWhatsApp).Error("Número de WhatsApp inválido."))
+		default:
+			number = append(number, validation.Match(brazilianMobile).Error("Número de celular inválido."))
+		}
+	}
 	return validation.ValidateStruct(p,
+		validation.Field(&p.Number, number...),
+		validation.Field(
+			&p.Type,
+			validation.Required.Error("Tipo do telefone vazio."),
+			validation.In(PhoneMobile, PhoneLandline, PhoneWhatsApp).Error("Tipo do telefone inválido."),
+		),
 		validation.Field(
-			&p.Number,
-			validation.Required.Error("Número de telefone não fornecido."),
-			isbr.Phone.Error("Formato do número é inválido."),
+			&p.Country,
+			validation.Required.Error("País do telefone vazio."),
+			is.CountryCode2.Error("País do telefone inválido."),
 		),
 	)
 }
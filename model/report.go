@@ -0,0 +1,139 @@
+package model
+
+import (
+	"strconv"
+
+	"github.com/go-ozzo/ozzo-validation"
+	"github.com/go-ozzo/ozzo-validation/is"
+	"github.com/mongodb/mongo-go-driver/bson/objectid"
+)
+
+// ReportFilter selects the users aggregated by a report, zero values don't filter.
+type ReportFilter struct {
+	// CourseID selects the users enrolled in the course
+	CourseID string
+	// MinAge and MaxAge select the users with age between them, inclusive
+	MinAge uint
+	MaxAge uint
+}
+
+// Validate validates the ReportFilter fields
+func (f ReportFilter) Validate() error {
+	maxAge := []validation.Rule{}
+	if f.MaxAge > 0 {
+		maxAge = append(maxAge, validation.Min(f.MinAge).Error("Idade máxima menor que a mínima."))
+	}
+	return validation.ValidateStruct(&f,
+		validation.Field(&f.CourseID, is.MongoID.Error("Curso inválido.")),
+		validation.Field(&f.MaxAge, maxAge...),
+	)
+}
+
+// Table is implemented by the reports that can be exported as CSV.
+type Table interface {
+	// Header returns the names of the columns
+	Header() []string
+	// Records returns the rows
+	Records() [][]string
+}
+
+// EnrollmentReport is the number of users enrolled in each course, most enrolled first.
+type EnrollmentReport []Enrollment
+
+// Enrollment is the number of users enrolled in a course.
+type Enrollment struct {
+	CourseID objectid.ObjectID `json:"course_id" bson:"_id"`
+	Course   string            `json:"course" bson:"course"`
+	Users    int               `json:"users" bson:"users"`
+}
+
+// Header returns the columns of the EnrollmentReport CSV.
+func (r EnrollmentReport) Header() []string {
+	return []string{"course_id", "course", "users"}
+}
+
+// Records returns the rows of the EnrollmentReport CSV.
+func (r EnrollmentReport) Records() (records [][]string) {
+	for _, e := range r {
+		records = append(records, []string{e.CourseID.Hex(), e.Course, strconv.Itoa(e.Users)})
+	}
+	return
+}
+
+// AgeReport is the number of users in each age bucket, by age.
+type AgeReport []AgeBucket
+
+// AgeBucket is the number of users with age from Min up to, but not including, Max.
+type AgeBucket struct {
+	Min   uint `json:"min"`
+	Max   uint `json:"max"`
+	Users int  `json:"users"`
+}
+
+// Header returns the columns of the AgeReport CSV.
+func (r AgeReport) Header() []string {
+	return []string{"min", "max", "users"}
+}
+
+// Records returns the rows of the AgeReport CSV.
+func (r AgeReport) Records() (records [][]string) {
+	for _, b := range r {
+		records = append(records, []string{strconv.Itoa(int(b.Min)), strconv.Itoa(int(b.Max)), strconv.Itoa(b.Users)})
+	}
+	return
+}
+
+// PhoneReport is the statistics of the number of phones of the users.
+type PhoneReport struct {
+	Users   int     `json:"users"`
+	Phones  int     `json:"phones"`
+	Average float64 `json:"average"`
+	Min     int     `json:"min"`
+	Max     int     `json:"max"`
+	// Distribution is the number of users with each number of phones
+	Distribution []PhoneCount `json:"distribution"`
+}
+
+// PhoneCount is the number of users with a number of phones.
+type PhoneCount struct {
+	Phones int `json:"phones" bson:"_id"`
+	Users  int `json:"users" bson:"users"`
+}
+
+// Header returns the columns of the distribution, the CSV has only the distribution.
+func (r PhoneReport) Header() []string {
+	return []string{"phones", "users"}
+}
+
+// Records returns the rows of the PhoneReport CSV.
+func (r PhoneReport) Records() (records [][]string) {
+	for _, c := range r.Distribution {
+		records = append(records, []string{strconv.Itoa(c.Phones), strconv.Itoa(c.Users)})
+	}
+	return
+}
+
+// AddressReportFields are the fields of Address that the users can be grouped by
+var AddressReportFields = []string{"state", "city", "neighborhood", "country"}
+
+// AddressReport is the number of users grouped by an address field, most users first.
+type AddressReport []AddressGroup
+
+// AddressGroup is the number of users with a value of the grouped address field.
+type AddressGroup struct {
+	Value string `json:"value" bson:"_id"`
+	Users int    `json:"users" bson:"users"`
+}
+
+// Header returns the columns of the AddressReport CSV.
+func (r AddressReport) Header() []string {
+	return []string{"value", "users"}
+}
+
+// Records returns the rows of the AddressReport CSV.
+func (r AddressReport) Records() (records [][]string) {
+	for _, g := range r {
+		records = append(records, []string{g.Value, strconv.Itoa(g.Users)})
+	}
+	return
+}
@@ -0,0 +1,13 @@
+package model
+
+// SearchResult represents a record found by a full-text search.
+type SearchResult struct {
+	// Type is the kind of the record, "user" or "course"
+	Type string `json:"type"`
+	// Score is the relevance of the record, higher first
+	Score float64 `json:"score"`
+	// Snippet is the name of the record, as HTML, with the matching words wrapped in <em>
+	Snippet string `json:"snippet"`
+	// Record is the User or the Course found
+	Record interface{} `json:"record"`
+}
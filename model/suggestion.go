@@ -0,0 +1,11 @@
+package model
+
+import (
+	"github.com/mongodb/mongo-go-driver/bson/objectid"
+)
+
+// Suggestion represents a record suggested while its name is typed.
+type Suggestion struct {
+	ID   objectid.ObjectID `json:"id" bson:"_id"`
+	Name string            `json:"name"`
+}
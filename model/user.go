@@ -1,15 +1,23 @@
 package model
 
 import (
+	"errors"
+	"strings"
+
 	"github.com/go-ozzo/ozzo-validation"
+	"github.com/go-ozzo/ozzo-validation/is"
+	"github.com/lucasfloriani/brazilian-ozzo-validation"
 	"github.com/mongodb/mongo-go-driver/bson/objectid"
 )
 
 // User represents an user record.
+// The CPF and the email are optional, but unique among the users.
 type User struct {
 	ID      objectid.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
 	Name    string            `json:"name,omitempty"`
 	Age     uint              `json:"age,omitempty"`
+	CPF     CPF               `json:"cpf,omitempty"`
+	Email   string            `json:"email,omitempty"`
 	Address Address           `json:"address"`
 	Phones  []Phone           `json:"phones"`
 	Courses []Course          `json:"courses"`
@@ -20,16 +28,29 @@ func NewUser() *User {
 	return &User{}
 }
 
+// Normalize stores the CPF as digits and the email in lower case
+func (u *User) Normalize() {
+	u.CPF = NormalizeCPF(string(u.CPF))
+	u.Email = strings.ToLower(strings.TrimSpace(u.Email))
+}
+
 // Validate validates the User fields
 func (u User) Validate() error {
 	if err := u.Address.Validate(); err != nil {
 		return err
 	}
 
+	primary := 0
 	for _, phone := range u.Phones {
 		if err := phone.Validate(); err != nil {
 			return err
 		}
+		if phone.Primary {
+			primary++
+		}
+	}
+	if primary > 1 {
+		return errors.New("Apenas um telefone pode ser o principal.")
 	}
 
 	return validation.ValidateStruct(&u,
@@ -43,6 +64,15 @@ func (u User) Validate() error {
 			validation.Required.Error("Idade vazia."),
 			validation.Min(uint(18)).Error("Idade mínima de 18 anos."),
 		),
+		validation.Field(
+			&u.CPF,
+			isbr.CPF.Error("CPF inválido."),
+		),
+		validation.Field(
+			&u.Email,
+			validation.Length(0, 254).Error("E-mail deve ter até 254 caracteres."),
+			is.Email.Error("E-mail inválido."),
+		),
 		validation.Field(
 			&u.Phones,
 			validation.Required.Error("É necessário pelo menos um telefone de contato."),
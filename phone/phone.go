@@ -0,0 +1,140 @@
+// Package phone normalizes the phone numbers to the E.164 format.
+package phone
+
+import (
+	"strings"
+
+	"github.com/lucasfloriani/go-mongo/model"
+)
+
+// DefaultCountry is the country of the phones without one
+const DefaultCountry = "BR"
+
+// callingCodes are the calling codes of the countries whose numbers can be written without it
+var callingCodes = map[string]string{
+	"BR": "55",
+	"AR": "54",
+	"BO": "591",
+	"CL": "56",
+	"CO": "57",
+	"PE": "51",
+	"PY": "595",
+	"UY": "598",
+	"VE": "58",
+	"MX": "52",
+	"US": "1",
+	"CA": "1",
+	"PT": "351",
+	"ES": "34",
+	"FR": "33",
+	"IT": "39",
+	"DE": "49",
+	"GB": "44",
+	"JP": "81",
+}
+
+// keepsTrunkPrefix are the countries whose national numbers start with 0 also in the international format
+var keepsTrunkPrefix = map[string]bool{"IT": true}
+
+// countries maps the calling codes to their country, the codes shared by several countries
+// (e.g. 1 of the US and Canada) to the most populous one
+var countries = map[string]string{"1": "US"}
+
+func init() {
+	for country, code := range callingCodes {
+		if _, ok := countries[code]; !ok {
+			countries[code] = country
+		}
+	}
+}
+
+// Normalize converts the number of the phone to the E.164 format and upper cases the country.
+// The missing country is inferred from the calling code of the number, or else defaults to
+// DefaultCountry. The type of the brazilian phones without one is inferred from the number.
+func Normalize(p *model.Phone) {
+	p.Country = strings.ToUpper(strings.TrimSpace(p.Country))
+	if p.Country == "" {
+		p.Country = DefaultCountry
+		if hasCallingCode(p.Number) {
+			p.Country = Country(E164(p.Number, ""))
+		}
+	}
+	p.Number = E164(p.Number, p.Country)
+	p.Type = strings.ToLower(strings.TrimSpace(p.Type))
+	if p.Type == "" && p.Country == "BR" {
+		// The brazilian mobiles have 9 digits after the area code, starting with 9, the landlines 8
+		if len(p.Number) == len("+5541999990000") && p.Number[5] == '9' {
+			p.Type = model.PhoneMobile
+		} else {
+			p.Type = model.PhoneLandline
+		}
+	}
+}
+
+// Country returns the country of the number in the E.164 format by its calling code,
+// or "" when the calling code is unknown.
+func Country(number string) string {
+	digits := strings.TrimPrefix(number, "+")
+	for n := 3; n > 0; n-- {
+		if len(digits) > n {
+			if country, ok := countries[digits[:n]]; ok {
+				return country
+			}
+		}
+	}
+	return ""
+}
+
+// E164 converts the number, formatted or not, to the E.164 format. The numbers starting with +
+// or 00 have the calling code, the others are national numbers of the country. The numbers of
+// unknown countries are returned trimmed, to fail the validation.
+func E164(number, country string) string {
+	digits := Digits(number)
+	trimmed := strings.TrimSpace(number)
+	if hasCallingCode(number) {
+		return "+" + strings.TrimPrefix(digits, "00")
+	}
+
+	country = strings.ToUpper(country)
+	code, ok := callingCodes[country]
+	if !ok {
+		return trimmed
+	}
+	if country == "BR" {
+		digits = national(digits)
+	} else if !keepsTrunkPrefix[country] {
+		digits = strings.TrimLeft(digits, "0")
+	}
+	return "+" + code + digits
+}
+
+// hasCallingCode check if the number starts with + or 00, followed by the calling code
+func hasCallingCode(number string) bool {
+	return strings.HasPrefix(strings.TrimSpace(number), "+") || strings.HasPrefix(Digits(number), "00")
+}
+
+// national removes the trunk prefix and the carrier code of a brazilian number, e.g. 0 41 or 0 15 41,
+// and the calling code written without +. The national numbers have 10 or 11 digits, with the area code.
+func national(digits string) string {
+	if strings.HasPrefix(digits, "0") {
+		digits = digits[1:]
+		if len(digits) == 12 || len(digits) == 13 {
+			digits = digits[2:]
+		}
+		return digits
+	}
+	if strings.HasPrefix(digits, "55") && (len(digits) == 12 || len(digits) == 13) {
+		return digits[2:]
+	}
+	return digits
+}
+
+// Digits removes everything but the digits of the number
+func Digits(number string) string {
+	return strings.Map(func(r rune) rune {
+		if r < '0' || r > '9' {
+			return -1
+		}
+		return r
+	}, number)
+}
@@ -0,0 +1,70 @@
+package phone
+
+import (
+	"testing"
+
+	"github.com/lucasfloriani/go-mongo/model"
+)
+
+func TestE164(t *testing.T) {
+	tests := []struct {
+		number, country, want string
+	}{
+		{"(41) 99999-0000", "BR", "+5541999990000"},
+		{"41 3333-0000", "BR", "+554133330000"},
+		{"0 41 99999-0000", "BR", "+5541999990000"},
+		{"0 15 41 99999-0000", "BR", "+5541999990000"},
+		{"55 41 99999-0000", "BR", "+5541999990000"},
+		{"+55 (41) 99999-0000", "BR", "+5541999990000"},
+		{"0055 41 99999-0000", "BR", "+5541999990000"},
+		{"+1 (202) 555-0123", "BR", "+12025550123"},
+		{"(202) 555-0123", "us", "+12025550123"},
+		{"020 7946 0018", "GB", "+442079460018"},
+		{"06 6982 0000", "IT", "+390669820000"},
+		{" 99999-0000 ", "ZZ", "99999-0000"},
+	}
+	for _, test := range tests {
+		if got := E164(test.number, test.country); got != test.want {
+			t.Errorf("E164(%q, %q) = %q, want %q", test.number, test.country, got, test.want)
+		}
+	}
+}
+
+func TestCountry(t *testing.T) {
+	tests := []struct {
+		number, want string
+	}{
+		{"+5541999990000", "BR"},
+		{"+12025550123", "US"},
+		{"+351912345678", "PT"},
+		{"+595981123456", "PY"},
+		{"+442079460018", "GB"},
+		{"+861012345678", ""},
+	}
+	for _, test := range tests {
+		if got := Country(test.number); got != test.want {
+			t.Errorf("Country(%q) = %q, want %q", test.number, got, test.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in, want model.Phone
+	}{
+		{model.Phone{Number: "(41) 99999-0000"}, model.Phone{Number: "+5541999990000", Type: model.PhoneMobile, Country: "BR"}},
+		{model.Phone{Number: "(41) 3333-0000"}, model.Phone{Number: "+554133330000", Type: model.PhoneLandline, Country: "BR"}},
+		{model.Phone{Number: "(41) 3333-0000", Type: " WhatsApp "}, model.Phone{Number: "+554133330000", Type: model.PhoneWhatsApp, Country: "BR"}},
+		{model.Phone{Number: "+1 202 555 0123", Type: "mobile"}, model.Phone{Number: "+12025550123", Type: model.PhoneMobile, Country: "US"}},
+		{model.Phone{Number: "00351 912 345 678", Type: "mobile"}, model.Phone{Number: "+351912345678", Type: model.PhoneMobile, Country: "PT"}},
+		{model.Phone{Number: "(202) 555-0123", Type: "landline", Country: " ca "}, model.Phone{Number: "+12025550123", Type: model.PhoneLandline, Country: "CA"}},
+		{model.Phone{Number: "+86 10 1234 5678", Type: "landline"}, model.Phone{Number: "+861012345678", Type: model.PhoneLandline}},
+	}
+	for _, test := range tests {
+		got := test.in
+		Normalize(&got)
+		if got != test.want {
+			t.Errorf("Normalize(%+v) = %+v, want %+v", test.in, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lucasfloriani/go-mongo/app"
+	"github.com/lucasfloriani/go-mongo/helper"
+
+	"github.com/labstack/echo"
+)
+
+// Middleware returns a middleware that limits the requests of each client with the rules of
+// app.Current().RateLimit, so reloads apply to the next requests. Clients are identified by the request identity or, when anonymous, by IP.
+// It must be used after the authentication middlewares.
+func Middleware(store Store) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			config := app.Current().RateLimit
+			if !config.Enabled {
+				return next(c)
+			}
+
+			rule := matchRule(config.Rules, c.Request().Method, c.Path())
+			limit := Limit{Requests: config.Default.Requests, Period: config.Default.Period, Burst: config.Default.Burst}
+			name := "default"
+			if rule != nil {
+				limit = Limit{Requests: rule.Requests, Period: rule.Period, Burst: rule.Burst}
+				name = rule.Method + " " + rule.Group
+			}
+
+			result, err := store.Take(name+"|"+clientKey(c), limit, time.Now())
+			if err != nil {
+				// A failing shared store must not take the API down with it
+				app.GetRequestScope(c).Logger().Error("rate limit not checked", "error", err)
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+			if !result.Allowed {
+				retryAfter := ceilSeconds(result.RetryAfter)
+				header.Set("Retry-After", strconv.Itoa(retryAfter))
+				err := fmt.Errorf("Limite de requisições excedido, tente novamente em %d segundos.", retryAfter)
+				return c.JSON(http.StatusTooManyRequests, helper.NewRequestErrorResponse(c, err))
+			}
+			return next(c)
+		}
+	}
+}
+
+// matchRule returns the rule with the longest group matching the route path and method.
+// Rules without method match every method, but lose to the ones with the same group and method.
+func matchRule(rules []app.RateLimitRule, method, path string) *app.RateLimitRule {
+	var match *app.RateLimitRule
+	for i, rule := range rules {
+		if !strings.HasPrefix(path, rule.Group) {
+			continue
+		}
+		if rule.Method != "" && !strings.EqualFold(rule.Method, method) {
+			continue
+		}
+		if match == nil ||
+			len(rule.Group) > len(match.Group) ||
+			len(rule.Group) == len(match.Group) && match.Method == "" {
+			match = &rules[i]
+		}
+	}
+	return match
+}
+
+// clientKey returns the key that identifies the client of the request: the API key that
+// authenticated it, so each key of an owner has its own limit, else the caller or the IP
+func clientKey(c echo.Context) string {
+	identity := app.GetRequestScope(c).Identity()
+	switch {
+	case identity.KeyID != "":
+		return "key:" + identity.KeyID
+	case identity.ID != "":
+		return "id:" + identity.ID
+	}
+	return "ip:" + c.RealIP()
+}
+
+// ceilSeconds rounds the duration up to whole seconds
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
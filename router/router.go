@@ -1,24 +1,96 @@
 package router
 
 import (
+	"context"
+
+	"github.com/lucasfloriani/go-mongo/app"
+	"github.com/lucasfloriani/go-mongo/auth"
 	"github.com/lucasfloriani/go-mongo/dao"
+	mongodb "github.com/lucasfloriani/go-mongo/db"
 	"github.com/lucasfloriani/go-mongo/handler"
+	"github.com/lucasfloriani/go-mongo/health"
+	"github.com/lucasfloriani/go-mongo/idempotency"
+	"github.com/lucasfloriani/go-mongo/metrics"
+	"github.com/lucasfloriani/go-mongo/ratelimit"
 	"github.com/lucasfloriani/go-mongo/service"
+	"github.com/lucasfloriani/go-mongo/tracing"
 
 	"github.com/labstack/echo"
 	"github.com/mongodb/mongo-go-driver/mongo"
 )
 
+// services are the services backed by the DAOs of the selected storage
+type services struct {
+	user       *service.UserService
+	course     *service.CourseService
+	apiKey     *service.APIKeyService
+	report     *service.ReportService
+	idempotent echo.MiddlewareFunc
+}
+
+// newServices creates the services with the DAOs of app.Config.Storage,
+// the memory ones don't use the database
+func newServices(db *mongo.Database) services {
+	if app.Config.Storage == "memory" {
+		users := dao.NewMemoryUserDAO()
+		return services{
+			user:       service.NewUserService(users),
+			course:     service.NewCourseService(dao.NewMemoryCourseDAO()),
+			apiKey:     service.NewAPIKeyService(dao.NewMemoryAPIKeyDAO()),
+			report:     service.NewReportService(dao.NewMemoryReportDAO(users)),
+			idempotent: idempotency.Middleware(dao.NewMemoryIdempotencyDAO()),
+		}
+	}
+	return services{
+		user:       service.NewUserService(dao.NewUserDAO(db)),
+		course:     service.NewCourseService(dao.NewCourseDAO(db)),
+		apiKey:     service.NewAPIKeyService(dao.NewAPIKeyDAO(db)),
+		report:     service.NewReportService(dao.NewReportDAO(db)),
+		idempotent: idempotency.Middleware(dao.NewIdempotencyDAO(db)),
+	}
+}
+
 // Setup creates routes from application with middlwares and handlers.
+// The database is nil when app.Config.Storage is "memory".
 func Setup(db *mongo.Database) *echo.Echo {
+	logger := app.Logger("router")
 	e := echo.New()
-	v1 := e.Group("/v1")
+	metrics.Init()
+	if db != nil {
+		if err := metrics.RegisterMongo(db); err != nil {
+			logger.Error("setup failed", "error", err)
+		}
+	}
+	if err := tracing.Init(); err != nil {
+		logger.Error("setup failed", "error", err)
+	}
+	e.Use(tracing.Middleware(), app.Init(), metrics.Middleware())
+
+	// Probes and metrics are mounted before the authentication and rate limiting of the API
+	checker := health.NewChecker()
+	checker.Add("config", health.Config)
+	checker.Add("lifecycle", health.Lifecycle)
+	checker.Add("workers", health.Workers)
+	if db != nil {
+		checker.Add("mongo", func(ctx context.Context) error { return mongodb.Ping(ctx, db) })
+	}
+	healthService := service.NewHealthService(checker)
+	handler.ServeHealthResource(e, healthService)
+	e.GET(app.Config.Metrics.Path, metrics.Handler())
 
-	userDAO := dao.NewUserDAO(db)
-	handler.ServeUserResource(v1, service.NewUserService(userDAO))
+	services := newServices(db)
+	// The status is read by the monitoring outside the rate limiting, detailed only with a key
+	handler.ServeStatusResource(e.Group("/v1", auth.APIKey(services.apiKey)), healthService)
+	v1 := e.Group("/v1",
+		auth.APIKey(services.apiKey),
+		ratelimit.Middleware(ratelimit.NewMemoryStore()),
+	)
 
-	courseDAO := dao.NewCourseDAO(db)
-	handler.ServeCourseResource(v1, service.NewCourseService(courseDAO))
+	handler.ServeUserResource(v1, services.user, services.idempotent)
+	handler.ServeCourseResource(v1, services.course, services.idempotent)
+	handler.ServeSearchResource(v1, service.NewSearchService(services.user, services.course))
+	handler.ServeReportResource(v1, services.report)
+	handler.ServeAPIKeyResource(v1, services.apiKey)
 
 	return e
 }
@@ -0,0 +1,97 @@
+// Package search normalizes and matches the text of the full-text search and the suggestions.
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxQueryLength limits the length of the searched text
+const MaxQueryLength = 100
+
+// Normalize returns the text in lower case without diacritics and repeated spaces,
+// so "  João " and "joao" are equal.
+func Normalize(text string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	normalized, _, err := transform.String(t, text)
+	if err != nil {
+		normalized = text
+	}
+	return strings.Join(strings.Fields(strings.ToLower(normalized)), " ")
+}
+
+// Terms returns the normalized words of the text.
+func Terms(text string) []string {
+	return strings.FieldsFunc(Normalize(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// match check if the normalized word matches the term, ignoring the suffixes
+// of the plurals and inflections (e.g. "curso" matches "cursos")
+func match(word, term string) bool {
+	return strings.HasPrefix(word, term) || len(word) >= 3 && strings.HasPrefix(term, word)
+}
+
+// matchAny check if the normalized word matches any of the terms
+func matchAny(word string, terms []string) bool {
+	for _, term := range terms {
+		if match(word, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// Score returns the ratio of the query terms found in the text, 0 when none is found.
+func Score(text, query string) float64 {
+	terms := Terms(query)
+	if len(terms) == 0 {
+		return 0
+	}
+	found := 0
+	words := Terms(text)
+	for _, term := range terms {
+		for _, word := range words {
+			if match(word, term) {
+				found++
+				break
+			}
+		}
+	}
+	return float64(found) / float64(len(terms))
+}
+
+// Highlight returns the text, escaped as HTML, with the words matching the query wrapped in <em>.
+func Highlight(text, query string) string {
+	terms := Terms(query)
+	var b strings.Builder
+	word := []rune{}
+	flush := func() {
+		if len(word) == 0 {
+			return
+		}
+		w := string(word)
+		if matchAny(Normalize(w), terms) {
+			b.WriteString("<em>" + html.EscapeString(w) + "</em>")
+		} else {
+			b.WriteString(html.EscapeString(w))
+		}
+		word = word[:0]
+	}
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.Is(unicode.Mn, r) {
+			word = append(word, r)
+			continue
+		}
+		flush()
+		b.WriteString(html.EscapeString(string(r)))
+	}
+	flush()
+	return b.String()
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/lucasfloriani/go-mongo/app"
+	"github.com/lucasfloriani/go-mongo/auth"
+	"github.com/lucasfloriani/go-mongo/model"
+
+	"github.com/mongodb/mongo-go-driver/mongo"
+)
+
+// apiKeyDAO specifies the interface of the API key DAO needed by APIKeyService.
+type apiKeyDAO interface {
+	All(ctx context.Context, offset, limit int) ([]model.APIKey, error)
+	Count(ctx context.Context) (int, error)
+	Get(ctx context.Context, id string) (*model.APIKey, error)
+	GetByHash(ctx context.Context, hash string) (*model.APIKey, error)
+	Create(ctx context.Context, k *model.APIKey) error
+	Update(ctx context.Context, k *model.APIKey) error
+	Touch(ctx context.Context, k *model.APIKey, usedAt time.Time) error
+}
+
+// APIKeyService provides services related with API keys.
+type APIKeyService struct {
+	dao apiKeyDAO
+}
+
+// NewAPIKeyService creates a new APIKeyService with the given API key DAO.
+func NewAPIKeyService(dao apiKeyDAO) *APIKeyService {
+	return &APIKeyService{dao}
+}
+
+// Count returns the number of API keys.
+func (s *APIKeyService) Count(rs app.RequestScope) (int, error) {
+	if err := auth.Authorize(rs, auth.APIKeyManage); err != nil {
+		return 0, err
+	}
+	return s.dao.Count(rs.Context())
+}
+
+// Query returns the API keys with the specified offset and limit.
+func (s *APIKeyService) Query(rs app.RequestScope, offset, limit int) ([]model.APIKey, error) {
+	if err := auth.Authorize(rs, auth.APIKeyManage); err != nil {
+		return nil, err
+	}
+	return s.dao.All(rs.Context(), offset, limit)
+}
+
+// Get returns the API key with the specified the API key ID.
+func (s *APIKeyService) Get(rs app.RequestScope, id string) (*model.APIKey, error) {
+	if err := auth.Authorize(rs, auth.APIKeyManage); err != nil {
+		return nil, err
+	}
+	return s.dao.Get(rs.Context(), id)
+}
+
+// Create creates a new API key, owned by the caller and limited to scopes the caller holds.
+// The returned APIKey.Key is the only time the plaintext key is available.
+func (s *APIKeyService) Create(rs app.RequestScope, k *model.APIKey) (*model.APIKey, error) {
+	if err := auth.Authorize(rs, auth.APIKeyManage); err != nil {
+		return nil, err
+	}
+	if err := k.Validate(); err != nil {
+		return nil, err
+	}
+	if err := validateScopes(k.Scopes); err != nil {
+		return nil, err
+	}
+	if err := auth.AuthorizeScopes(rs, k.Scopes); err != nil {
+		return nil, err
+	}
+	if err := generateKey(k); err != nil {
+		return nil, err
+	}
+	// The key acts as its owner, so it can only be the caller
+	k.Owner = rs.Identity().ID
+	k.CreatedAt = time.Now()
+	k.LastUsedAt = time.Time{}
+	k.Revoked = false
+	if err := s.dao.Create(rs.Context(), k); err != nil {
+		return nil, err
+	}
+	rs.Logger().Info("api key created", "key_id", k.ID.Hex(), "prefix", k.Prefix, "owner", k.Owner)
+	return k, nil
+}
+
+// Rotate replaces the secret of the API key with the specified ID, keeping its scopes and owner.
+// The returned APIKey.Key is the only time the new plaintext key is available.
+func (s *APIKeyService) Rotate(rs app.RequestScope, id string) (*model.APIKey, error) {
+	if err := auth.Authorize(rs, auth.APIKeyManage); err != nil {
+		return nil, err
+	}
+	k, err := s.dao.Get(rs.Context(), id)
+	if err != nil {
+		return nil, err
+	}
+	if err := generateKey(k); err != nil {
+		return nil, err
+	}
+	if err := s.dao.Update(rs.Context(), k); err != nil {
+		return nil, err
+	}
+	rs.Logger().Info("api key rotated", "key_id", k.ID.Hex(), "prefix", k.Prefix)
+	return k, nil
+}
+
+// Revoke revokes the API key with the specified ID.
+func (s *APIKeyService) Revoke(rs app.RequestScope, id string) (*model.APIKey, error) {
+	if err := auth.Authorize(rs, auth.APIKeyManage); err != nil {
+		return nil, err
+	}
+	k, err := s.dao.Get(rs.Context(), id)
+	if err != nil {
+		return nil, err
+	}
+	k.Revoked = true
+	if err := s.dao.Update(rs.Context(), k); err != nil {
+		return nil, err
+	}
+	rs.Logger().Info("api key revoked", "key_id", k.ID.Hex(), "prefix", k.Prefix)
+	return k, nil
+}
+
+// Authenticate returns the identity of the owner of the given plaintext key, limited to the key scopes.
+// Unknown, revoked and expired keys return auth.ErrInvalidAPIKey, other errors are failures of the database.
+func (s *APIKeyService) Authenticate(ctx context.Context, key string) (app.Identity, error) {
+	k, err := s.dao.GetByHash(ctx, hashKey(key))
+	if err == mongo.ErrNoDocuments {
+		return app.Identity{}, auth.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return app.Identity{}, err
+	}
+	now := time.Now()
+	if k.Revoked || k.Expired(now) {
+		return app.Identity{}, auth.ErrInvalidAPIKey
+	}
+	if err := s.dao.Touch(ctx, k, now); err != nil {
+		return app.Identity{}, err
+	}
+	return app.Identity{ID: k.Owner, Scopes: k.Scopes, KeyID: k.ID.Hex()}, nil
+}
+
+// validateScopes check if every scope is a known permission
+func validateScopes(scopes []string) error {
+	for _, scope := range scopes {
+		if !auth.IsPermission(scope) {
+			return fmt.Errorf("Escopo inválido: %s.", scope)
+		}
+	}
+	return nil
+}
+
+// generateKey fills the key with a new random plaintext, its prefix and hash
+func generateKey(k *model.APIKey) error {
+	prefix := make([]byte, 4)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(prefix); err != nil {
+		return err
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+	k.Prefix = hex.EncodeToString(prefix)
+	k.Key = k.Prefix + "." + base64.RawURLEncoding.EncodeToString(secret)
+	k.Hash = hashKey(k.Key)
+	return nil
+}
+
+// hashKey returns the hash stored in database for a plaintext key
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
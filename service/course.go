@@ -1,17 +1,26 @@
 package service
 
 import (
+	"context"
+
+	"github.com/lucasfloriani/go-mongo/app"
+	"github.com/lucasfloriani/go-mongo/auth"
 	"github.com/lucasfloriani/go-mongo/model"
+	"github.com/lucasfloriani/go-mongo/search"
+	"github.com/lucasfloriani/go-mongo/tracing"
 )
 
 // courseDAO specifies the interface of the course DAO needed by CourseService.
 type courseDAO interface {
-	All(offset, limit int) ([]model.Course, error)
-	Count() (int, error)
-	Get(id string) (*model.Course, error)
-	Create(u *model.Course) error
-	Update(u *model.Course) error
-	Delete(u *model.Course) error
+	All(ctx context.Context, offset, limit int) ([]model.Course, error)
+	Count(ctx context.Context) (int, error)
+	Search(ctx context.Context, query string, offset, limit int) ([]model.SearchResult, error)
+	CountSearch(ctx context.Context, query string) (int, error)
+	Suggest(ctx context.Context, prefix string, limit int) ([]model.Suggestion, error)
+	Get(ctx context.Context, id string) (*model.Course, error)
+	Create(ctx context.Context, u *model.Course) error
+	Update(ctx context.Context, u *model.Course) error
+	Delete(ctx context.Context, u *model.Course) error
 }
 
 // CourseService provides services related with courses.
@@ -25,48 +34,137 @@ func NewCourseService(dao courseDAO) *CourseService {
 }
 
 // Count returns the number of courses.
-func (s *CourseService) Count() (int, error) {
-	return s.dao.Count()
+func (s *CourseService) Count(rs app.RequestScope) (count int, err error) {
+	ctx, span := tracing.Start(rs.Context(), "CourseService.Count")
+	defer func() { tracing.End(span, err) }()
+
+	if err := auth.Authorize(rs, auth.CourseRead); err != nil {
+		return 0, err
+	}
+	return s.dao.Count(ctx)
 }
 
 // Query returns the courses with the specified offset and limit.
-func (s *CourseService) Query(offset, limit int) ([]model.Course, error) {
-	return s.dao.All(offset, limit)
+func (s *CourseService) Query(rs app.RequestScope, offset, limit int) (courses []model.Course, err error) {
+	ctx, span := tracing.Start(rs.Context(), "CourseService.Query")
+	defer func() { tracing.End(span, err) }()
+
+	if err := auth.Authorize(rs, auth.CourseRead); err != nil {
+		return nil, err
+	}
+	return s.dao.All(ctx, offset, limit)
+}
+
+// CountSearch returns the number of courses matching the full-text query.
+func (s *CourseService) CountSearch(rs app.RequestScope, query string) (count int, err error) {
+	ctx, span := tracing.Start(rs.Context(), "CourseService.CountSearch")
+	defer func() { tracing.End(span, err) }()
+
+	if err := auth.Authorize(rs, auth.CourseRead); err != nil {
+		return 0, err
+	}
+	if err := validateQuery(query); err != nil {
+		return 0, err
+	}
+	return s.dao.CountSearch(ctx, query)
+}
+
+// Search returns the courses matching the full-text query, by relevance, with the specified offset and limit.
+func (s *CourseService) Search(rs app.RequestScope, query string, offset, limit int) (results []model.SearchResult, err error) {
+	ctx, span := tracing.Start(rs.Context(), "CourseService.Search")
+	defer func() { tracing.End(span, err) }()
+
+	if err := auth.Authorize(rs, auth.CourseRead); err != nil {
+		return nil, err
+	}
+	if err := validateQuery(query); err != nil {
+		return nil, err
+	}
+	if results, err = s.dao.Search(ctx, query, offset, limit); err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Snippet = search.Highlight(results[i].Record.(model.Course).Name, query)
+	}
+	return results, nil
+}
+
+// Suggest returns the courses whose name starts with the prefix, by name up to the limit.
+func (s *CourseService) Suggest(rs app.RequestScope, prefix string, limit int) (suggestions []model.Suggestion, err error) {
+	ctx, span := tracing.Start(rs.Context(), "CourseService.Suggest")
+	defer func() { tracing.End(span, err) }()
+
+	if err := auth.Authorize(rs, auth.CourseRead); err != nil {
+		return nil, err
+	}
+	if err := validatePrefix(prefix); err != nil {
+		return nil, err
+	}
+	return s.dao.Suggest(ctx, prefix, suggestionLimit(limit))
 }
 
 // Get returns the course with the specified the course ID.
-func (s *CourseService) Get(id string) (*model.Course, error) {
-	return s.dao.Get(id)
+func (s *CourseService) Get(rs app.RequestScope, id string) (course *model.Course, err error) {
+	ctx, span := tracing.Start(rs.Context(), "CourseService.Get")
+	defer func() { tracing.End(span, err) }()
+
+	if err := auth.Authorize(rs, auth.CourseRead); err != nil {
+		return nil, err
+	}
+	return s.dao.Get(ctx, id)
 }
 
 // Create creates a new course.
-func (s *CourseService) Create(u *model.Course) (*model.Course, error) {
+func (s *CourseService) Create(rs app.RequestScope, u *model.Course) (course *model.Course, err error) {
+	ctx, span := tracing.Start(rs.Context(), "CourseService.Create")
+	defer func() { tracing.End(span, err) }()
+
+	if err := auth.Authorize(rs, auth.CourseCreate); err != nil {
+		return nil, err
+	}
 	if err := u.Validate(); err != nil {
 		return nil, err
 	}
-	if err := s.dao.Create(u); err != nil {
+	if err := s.dao.Create(ctx, u); err != nil {
 		return nil, err
 	}
+	rs.Logger().Info("course created", "course_id", u.ID.Hex())
 	return u, nil
 }
 
 // Update updates the course with the specified ID.
-func (s *CourseService) Update(u *model.Course) (*model.Course, error) {
+func (s *CourseService) Update(rs app.RequestScope, u *model.Course) (course *model.Course, err error) {
+	ctx, span := tracing.Start(rs.Context(), "CourseService.Update")
+	defer func() { tracing.End(span, err) }()
+
+	if err := auth.Authorize(rs, auth.CourseUpdate); err != nil {
+		return nil, err
+	}
 	if err := u.Validate(); err != nil {
 		return nil, err
 	}
-	if err := s.dao.Update(u); err != nil {
+	if err := s.dao.Update(ctx, u); err != nil {
 		return nil, err
 	}
+	rs.Logger().Info("course updated", "course_id", u.ID.Hex())
 	return u, nil
 }
 
 // Delete deletes the course with the specified ID.
-func (s *CourseService) Delete(id string) (*model.Course, error) {
-	course, err := s.dao.Get(id)
+func (s *CourseService) Delete(rs app.RequestScope, id string) (course *model.Course, err error) {
+	ctx, span := tracing.Start(rs.Context(), "CourseService.Delete")
+	defer func() { tracing.End(span, err) }()
+
+	if err := auth.Authorize(rs, auth.CourseDelete); err != nil {
+		return nil, err
+	}
+	course, err = s.dao.Get(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	err = s.dao.Delete(course)
-	return course, err
+	if err = s.dao.Delete(ctx, course); err != nil {
+		return nil, err
+	}
+	rs.Logger().Info("course deleted", "course_id", id)
+	return course, nil
 }
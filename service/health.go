@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+
+	"github.com/lucasfloriani/go-mongo/app"
+	"github.com/lucasfloriani/go-mongo/auth"
+	"github.com/lucasfloriani/go-mongo/health"
+)
+
+// HealthService provides services related with the health of the application.
+type HealthService struct {
+	checker *health.Checker
+}
+
+// NewHealthService creates a new HealthService with the given checker.
+func NewHealthService(checker *health.Checker) *HealthService {
+	return &HealthService{checker}
+}
+
+// Ready returns the report of the dependencies checks.
+func (s *HealthService) Ready(ctx context.Context) health.Report {
+	return s.checker.Run(ctx)
+}
+
+// Status returns the detailed report of the dependencies checks, or only the overall status
+// when the caller hasn't the status:read permission.
+func (s *HealthService) Status(rs app.RequestScope) health.Report {
+	report := s.checker.Run(rs.Context())
+	if auth.Authorize(rs, auth.StatusRead) != nil {
+		return health.Report{Status: report.Status}
+	}
+	return report
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lucasfloriani/go-mongo/app"
+	"github.com/lucasfloriani/go-mongo/auth"
+	"github.com/lucasfloriani/go-mongo/model"
+	"github.com/lucasfloriani/go-mongo/tracing"
+
+	"github.com/go-ozzo/ozzo-validation"
+)
+
+// reportDAO specifies the interface of the report DAO needed by ReportService.
+type reportDAO interface {
+	Enrollments(ctx context.Context, filter model.ReportFilter) (model.EnrollmentReport, error)
+	Ages(ctx context.Context, filter model.ReportFilter, boundaries []uint) (model.AgeReport, error)
+	Phones(ctx context.Context, filter model.ReportFilter) (*model.PhoneReport, error)
+	Addresses(ctx context.Context, filter model.ReportFilter, field string) (model.AddressReport, error)
+}
+
+// ReportService provides the aggregated reports of the users.
+type ReportService struct {
+	dao reportDAO
+}
+
+// NewReportService creates a new ReportService with the given report DAO.
+func NewReportService(dao reportDAO) *ReportService {
+	return &ReportService{dao}
+}
+
+// Enrollments returns the number of users enrolled in each course.
+func (s *ReportService) Enrollments(rs app.RequestScope, filter model.ReportFilter) (report model.EnrollmentReport, err error) {
+	ctx, span := tracing.Start(rs.Context(), "ReportService.Enrollments")
+	defer func() { tracing.End(span, err) }()
+
+	if err := authorizeReport(rs, filter); err != nil {
+		return nil, err
+	}
+	return s.dao.Enrollments(ctx, filter)
+}
+
+// Ages returns the histogram of the ages of the users, with the buckets between the boundaries.
+// The boundaries configured in app.Config.Reports.AgeBuckets are used when none is given.
+func (s *ReportService) Ages(rs app.RequestScope, filter model.ReportFilter, boundaries []uint) (report model.AgeReport, err error) {
+	ctx, span := tracing.Start(rs.Context(), "ReportService.Ages")
+	defer func() { tracing.End(span, err) }()
+
+	if err := authorizeReport(rs, filter); err != nil {
+		return nil, err
+	}
+	if len(boundaries) == 0 {
+		boundaries = app.Current().Reports.AgeBuckets
+	}
+	if err := validateBoundaries(boundaries); err != nil {
+		return nil, err
+	}
+	return s.dao.Ages(ctx, filter, boundaries)
+}
+
+// Phones returns the statistics of the number of phones of the users.
+func (s *ReportService) Phones(rs app.RequestScope, filter model.ReportFilter) (report *model.PhoneReport, err error) {
+	ctx, span := tracing.Start(rs.Context(), "ReportService.Phones")
+	defer func() { tracing.End(span, err) }()
+
+	if err := authorizeReport(rs, filter); err != nil {
+		return nil, err
+	}
+	return s.dao.Phones(ctx, filter)
+}
+
+// Addresses returns the number of users grouped by the address field.
+func (s *ReportService) Addresses(rs app.RequestScope, filter model.ReportFilter, field string) (report model.AddressReport, err error) {
+	ctx, span := tracing.Start(rs.Context(), "ReportService.Addresses")
+	defer func() { tracing.End(span, err) }()
+
+	if err := authorizeReport(rs, filter); err != nil {
+		return nil, err
+	}
+	fields := make([]interface{}, len(model.AddressReportFields))
+	for i, f := range model.AddressReportFields {
+		fields[i] = f
+	}
+	if err := validation.Validate(field, validation.Required.Error("Campo do endereço vazio."), validation.In(fields...).Error("Campo do endereço inválido.")); err != nil {
+		return nil, err
+	}
+	return s.dao.Addresses(ctx, filter, field)
+}
+
+// authorizeReport check if the caller may read the reports and the filter is valid
+func authorizeReport(rs app.RequestScope, filter model.ReportFilter) error {
+	if err := auth.Authorize(rs, auth.ReportRead); err != nil {
+		return err
+	}
+	return filter.Validate()
+}
+
+// validateBoundaries check if there are at least two boundaries, in ascending order
+func validateBoundaries(boundaries []uint) error {
+	if len(boundaries) < 2 {
+		return errors.New("São necessários pelo menos dois limites de idade.")
+	}
+	for i := 1; i < len(boundaries); i++ {
+		if boundaries[i] <= boundaries[i-1] {
+			return errors.New("Limites de idade devem estar em ordem crescente.")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lucasfloriani/go-mongo/app"
+	"github.com/lucasfloriani/go-mongo/auth"
+	"github.com/lucasfloriani/go-mongo/model"
+	"github.com/lucasfloriani/go-mongo/search"
+	"github.com/lucasfloriani/go-mongo/tracing"
+
+	"github.com/go-ozzo/ozzo-validation"
+)
+
+const (
+	// DefaultSuggestions is the number of suggestions returned when no limit is given
+	DefaultSuggestions = 10
+	// MaxSuggestions limits the number of suggestions
+	MaxSuggestions = 20
+)
+
+// searcher specifies the interface of the services searched by SearchService.
+type searcher interface {
+	Search(rs app.RequestScope, query string, offset, limit int) ([]model.SearchResult, error)
+	CountSearch(rs app.RequestScope, query string) (int, error)
+}
+
+// SearchService provides the full-text search across users and courses.
+type SearchService struct {
+	searchers []searcher
+}
+
+// NewSearchService creates a new SearchService over the given services.
+func NewSearchService(searchers ...searcher) *SearchService {
+	return &SearchService{searchers}
+}
+
+// Count returns the number of records matching the full-text query
+// among the ones the caller may read.
+func (s *SearchService) Count(rs app.RequestScope, query string) (count int, err error) {
+	_, span := tracing.Start(rs.Context(), "SearchService.Count")
+	defer func() { tracing.End(span, err) }()
+
+	if err := validateQuery(query); err != nil {
+		return 0, err
+	}
+	for _, searcher := range s.searchers {
+		n, err := searcher.CountSearch(rs, query)
+		if _, denied := err.(*auth.PermissionError); denied {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		count += n
+	}
+	return count, nil
+}
+
+// Query returns the records matching the full-text query among the ones the caller may read,
+// by relevance, with the specified offset and limit.
+func (s *SearchService) Query(rs app.RequestScope, query string, offset, limit int) (results []model.SearchResult, err error) {
+	_, span := tracing.Start(rs.Context(), "SearchService.Query")
+	defer func() { tracing.End(span, err) }()
+
+	if err := validateQuery(query); err != nil {
+		return nil, err
+	}
+	// The page can have records of any service, so the first offset+limit of each are merged
+	for _, searcher := range s.searchers {
+		found, err := searcher.Search(rs, query, 0, offset+limit)
+		if _, denied := err.(*auth.PermissionError); denied {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, found...)
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+
+	if offset > len(results) {
+		offset = len(results)
+	}
+	results = results[offset:]
+	if limit < len(results) {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// validateQuery check if the full-text query has words and isn't too long
+func validateQuery(query string) error {
+	return validation.Validate(strings.TrimSpace(query),
+		validation.Required.Error("Termo de busca vazio."),
+		validation.RuneLength(1, search.MaxQueryLength).Error(fmt.Sprintf("Termo de busca deve ter até %d caracteres.", search.MaxQueryLength)),
+	)
+}
+
+// validatePrefix check if the prefix of the suggestions has a letter and isn't too long
+func validatePrefix(prefix string) error {
+	return validation.Validate(search.Normalize(prefix),
+		validation.Required.Error("Prefixo vazio."),
+		validation.RuneLength(1, search.MaxQueryLength).Error(fmt.Sprintf("Prefixo deve ter até %d caracteres.", search.MaxQueryLength)),
+	)
+}
+
+// suggestionLimit returns the limit of suggestions between 1 and MaxSuggestions, DefaultSuggestions when not given
+func suggestionLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultSuggestions
+	}
+	if limit > MaxSuggestions {
+		return MaxSuggestions
+	}
+	return limit
+}
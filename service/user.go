@@ -1,17 +1,39 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/lucasfloriani/go-mongo/address"
+	"github.com/lucasfloriani/go-mongo/app"
+	"github.com/lucasfloriani/go-mongo/auth"
 	"github.com/lucasfloriani/go-mongo/model"
+	"github.com/lucasfloriani/go-mongo/phone"
+	"github.com/lucasfloriani/go-mongo/search"
+	"github.com/lucasfloriani/go-mongo/tracing"
+
+	"github.com/go-ozzo/ozzo-validation"
+	"github.com/go-ozzo/ozzo-validation/is"
+	"github.com/lucasfloriani/brazilian-ozzo-validation"
+	"github.com/mongodb/mongo-go-driver/mongo"
 )
 
 // userDAO specifies the interface of the user DAO needed by UserService.
 type userDAO interface {
-	All(offset, limit int) ([]model.User, error)
-	Count() (int, error)
-	Get(id string) (*model.User, error)
-	Create(u *model.User) error
-	Update(u *model.User) error
-	Delete(u *model.User) error
+	All(ctx context.Context, offset, limit int) ([]model.User, error)
+	Count(ctx context.Context) (int, error)
+	ByPhone(ctx context.Context, number string, offset, limit int) ([]model.User, error)
+	CountByPhone(ctx context.Context, number string) (int, error)
+	Search(ctx context.Context, query string, offset, limit int) ([]model.SearchResult, error)
+	CountSearch(ctx context.Context, query string) (int, error)
+	Suggest(ctx context.Context, prefix string, limit int) ([]model.Suggestion, error)
+	Get(ctx context.Context, id string) (*model.User, error)
+	GetByCPF(ctx context.Context, cpf model.CPF) (*model.User, error)
+	GetByEmail(ctx context.Context, email string) (*model.User, error)
+	Create(ctx context.Context, u *model.User) error
+	Update(ctx context.Context, u *model.User) error
+	Delete(ctx context.Context, u *model.User) error
 }
 
 // UserService provides services related with users.
@@ -25,48 +47,267 @@ func NewUserService(dao userDAO) *UserService {
 }
 
 // Count returns the number of users.
-func (s *UserService) Count() (int, error) {
-	return s.dao.Count()
+func (s *UserService) Count(rs app.RequestScope) (count int, err error) {
+	ctx, span := tracing.Start(rs.Context(), "UserService.Count")
+	defer func() { tracing.End(span, err) }()
+
+	if err := auth.Authorize(rs, auth.UserRead); err != nil {
+		return 0, err
+	}
+	return s.dao.Count(ctx)
 }
 
 // Query returns the users with the specified offset and limit.
-func (s *UserService) Query(offset, limit int) ([]model.User, error) {
-	return s.dao.All(offset, limit)
+func (s *UserService) Query(rs app.RequestScope, offset, limit int) (users []model.User, err error) {
+	ctx, span := tracing.Start(rs.Context(), "UserService.Query")
+	defer func() { tracing.End(span, err) }()
+
+	if err := auth.Authorize(rs, auth.UserRead); err != nil {
+		return nil, err
+	}
+	return s.dao.All(ctx, offset, limit)
+}
+
+// CountByPhone returns the number of users with the phone number, formatted in any way.
+func (s *UserService) CountByPhone(rs app.RequestScope, number string) (count int, err error) {
+	ctx, span := tracing.Start(rs.Context(), "UserService.CountByPhone")
+	defer func() { tracing.End(span, err) }()
+
+	if err := auth.Authorize(rs, auth.UserRead); err != nil {
+		return 0, err
+	}
+	number, err = normalizePhone(number)
+	if err != nil {
+		return 0, err
+	}
+	return s.dao.CountByPhone(ctx, number)
+}
+
+// ByPhone returns the users with the phone number, formatted in any way, with the specified offset and limit.
+// The numbers without calling code are taken as brazilian.
+func (s *UserService) ByPhone(rs app.RequestScope, number string, offset, limit int) (users []model.User, err error) {
+	ctx, span := tracing.Start(rs.Context(), "UserService.ByPhone")
+	defer func() { tracing.End(span, err) }()
+
+	if err := auth.Authorize(rs, auth.UserRead); err != nil {
+		return nil, err
+	}
+	number, err = normalizePhone(number)
+	if err != nil {
+		return nil, err
+	}
+	return s.dao.ByPhone(ctx, number, offset, limit)
+}
+
+// CountSearch returns the number of users matching the full-text query.
+func (s *UserService) CountSearch(rs app.RequestScope, query string) (count int, err error) {
+	ctx, span := tracing.Start(rs.Context(), "UserService.CountSearch")
+	defer func() { tracing.End(span, err) }()
+
+	if err := auth.Authorize(rs, auth.UserRead); err != nil {
+		return 0, err
+	}
+	if err := validateQuery(query); err != nil {
+		return 0, err
+	}
+	return s.dao.CountSearch(ctx, query)
+}
+
+// Search returns the users matching the full-text query, by relevance, with the specified offset and limit.
+func (s *UserService) Search(rs app.RequestScope, query string, offset, limit int) (results []model.SearchResult, err error) {
+	ctx, span := tracing.Start(rs.Context(), "UserService.Search")
+	defer func() { tracing.End(span, err) }()
+
+	if err := auth.Authorize(rs, auth.UserRead); err != nil {
+		return nil, err
+	}
+	if err := validateQuery(query); err != nil {
+		return nil, err
+	}
+	if results, err = s.dao.Search(ctx, query, offset, limit); err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Snippet = search.Highlight(results[i].Record.(model.User).Name, query)
+	}
+	return results, nil
+}
+
+// Suggest returns the users whose name starts with the prefix, by name up to the limit.
+func (s *UserService) Suggest(rs app.RequestScope, prefix string, limit int) (suggestions []model.Suggestion, err error) {
+	ctx, span := tracing.Start(rs.Context(), "UserService.Suggest")
+	defer func() { tracing.End(span, err) }()
+
+	if err := auth.Authorize(rs, auth.UserRead); err != nil {
+		return nil, err
+	}
+	if err := validatePrefix(prefix); err != nil {
+		return nil, err
+	}
+	return s.dao.Suggest(ctx, prefix, suggestionLimit(limit))
 }
 
 // Get returns the user with the specified the user ID.
-func (s *UserService) Get(id string) (*model.User, error) {
-	return s.dao.Get(id)
+func (s *UserService) Get(rs app.RequestScope, id string) (user *model.User, err error) {
+	ctx, span := tracing.Start(rs.Context(), "UserService.Get")
+	defer func() { tracing.End(span, err) }()
+
+	if err := auth.AuthorizeOwner(rs, auth.UserRead, id); err != nil {
+		return nil, err
+	}
+	return s.dao.Get(ctx, id)
+}
+
+// GetByCPF returns the user with the specified CPF, formatted or not.
+func (s *UserService) GetByCPF(rs app.RequestScope, cpf string) (user *model.User, err error) {
+	ctx, span := tracing.Start(rs.Context(), "UserService.GetByCPF")
+	defer func() { tracing.End(span, err) }()
+
+	if err := auth.Authorize(rs, auth.UserLookup); err != nil {
+		return nil, err
+	}
+	normalized := model.NormalizeCPF(cpf)
+	if err := validation.Validate(normalized, validation.Required.Error("CPF vazio."), isbr.CPF.Error("CPF inválido.")); err != nil {
+		return nil, err
+	}
+	return s.dao.GetByCPF(ctx, normalized)
+}
+
+// GetByEmail returns the user with the specified email, ignoring case.
+func (s *UserService) GetByEmail(rs app.RequestScope, email string) (user *model.User, err error) {
+	ctx, span := tracing.Start(rs.Context(), "UserService.GetByEmail")
+	defer func() { tracing.End(span, err) }()
+
+	if err := auth.Authorize(rs, auth.UserLookup); err != nil {
+		return nil, err
+	}
+	normalized := strings.ToLower(strings.TrimSpace(email))
+	if err := validation.Validate(normalized, validation.Required.Error("E-mail vazio."), is.Email.Error("E-mail inválido.")); err != nil {
+		return nil, err
+	}
+	return s.dao.GetByEmail(ctx, normalized)
 }
 
 // Create creates a new user.
-func (s *UserService) Create(u *model.User) (*model.User, error) {
+func (s *UserService) Create(rs app.RequestScope, u *model.User) (user *model.User, err error) {
+	ctx, span := tracing.Start(rs.Context(), "UserService.Create")
+	defer func() { tracing.End(span, err) }()
+
+	if err := auth.Authorize(rs, auth.UserCreate); err != nil {
+		return nil, err
+	}
+	u.Normalize()
+	address.Normalize(&u.Address)
+	for i := range u.Phones {
+		phone.Normalize(&u.Phones[i])
+	}
 	if err := u.Validate(); err != nil {
 		return nil, err
 	}
-	if err := s.dao.Create(u); err != nil {
+	if err := address.Check(u.Address); err != nil {
+		return nil, err
+	}
+	if err := s.checkIdentity(ctx, u); err != nil {
+		return nil, err
+	}
+	if err := s.dao.Create(ctx, u); err != nil {
 		return nil, err
 	}
+	rs.Logger().Info("user created", "user_id", u.ID.Hex())
 	return u, nil
 }
 
 // Update updates the user with the specified ID.
-func (s *UserService) Update(u *model.User) (*model.User, error) {
+func (s *UserService) Update(rs app.RequestScope, u *model.User) (user *model.User, err error) {
+	ctx, span := tracing.Start(rs.Context(), "UserService.Update")
+	defer func() { tracing.End(span, err) }()
+
+	if err := auth.AuthorizeOwner(rs, auth.UserUpdate, u.ID.Hex()); err != nil {
+		return nil, err
+	}
+	u.Normalize()
+	address.Normalize(&u.Address)
+	for i := range u.Phones {
+		phone.Normalize(&u.Phones[i])
+	}
 	if err := u.Validate(); err != nil {
 		return nil, err
 	}
-	if err := s.dao.Update(u); err != nil {
+	if err := address.Check(u.Address); err != nil {
+		return nil, err
+	}
+	if err := s.checkIdentity(ctx, u); err != nil {
 		return nil, err
 	}
+	if err := s.dao.Update(ctx, u); err != nil {
+		return nil, err
+	}
+	rs.Logger().Info("user updated", "user_id", u.ID.Hex())
 	return u, nil
 }
 
 // Delete deletes the user with the specified ID.
-func (s *UserService) Delete(id string) (*model.User, error) {
-	user, err := s.dao.Get(id)
+func (s *UserService) Delete(rs app.RequestScope, id string) (user *model.User, err error) {
+	ctx, span := tracing.Start(rs.Context(), "UserService.Delete")
+	defer func() { tracing.End(span, err) }()
+
+	if err := auth.AuthorizeOwner(rs, auth.UserDelete, id); err != nil {
+		return nil, err
+	}
+	user, err = s.dao.Get(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	err = s.dao.Delete(user)
-	return user, err
+	if err = s.dao.Delete(ctx, user); err != nil {
+		return nil, err
+	}
+	rs.Logger().Info("user deleted", "user_id", id)
+	return user, nil
+}
+
+// normalizePhone converts the searched phone number to the E.164 format, like the stored ones.
+// A leading space is taken as the + of the calling code, decoded as a space from the query string
+// when not escaped
+func normalizePhone(number string) (string, error) {
+	if strings.HasPrefix(number, " ") && !strings.HasPrefix(strings.TrimSpace(number), "+") {
+		number = "+" + strings.TrimSpace(number)
+	}
+	number = phone.E164(number, phone.DefaultCountry)
+	err := validation.Validate(number,
+		validation.Required.Error("Número de telefone não fornecido."),
+		validation.Match(model.E164).Error("Formato do número é inválido."),
+	)
+	return number, err
+}
+
+// checkIdentity check if the CPF and the email of the user aren't used by another user,
+// the unique indexes reject the ones that pass concurrently
+func (s *UserService) checkIdentity(ctx context.Context, u *model.User) error {
+	if u.CPF != "" {
+		other, err := s.dao.GetByCPF(ctx, u.CPF)
+		if err := checkUnused(u, other, err, "CPF já cadastrado."); err != nil {
+			return err
+		}
+	}
+	if u.Email != "" {
+		other, err := s.dao.GetByEmail(ctx, u.Email)
+		if err := checkUnused(u, other, err, "E-mail já cadastrado."); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkUnused returns the message as error when other, found with err, is another user than u
+func checkUnused(u, other *model.User, err error, message string) error {
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if other.ID != u.ID {
+		return errors.New(message)
+	}
+	return nil
 }
@@ -0,0 +1,56 @@
+package tracing
+
+import (
+	"fmt"
+
+	"github.com/labstack/echo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware returns a middleware that starts a span per request, continuing the trace of the
+// incoming traceparent header and returning the traceparent of the request span in the response.
+// It must run before app.Init, so the request scope carries the span.
+func Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			propagator := otel.GetTextMapPropagator()
+			ctx := propagator.Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+
+			ctx, span := Start(ctx, req.Method+" "+req.URL.Path,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPMethod(req.Method),
+					semconv.HTTPTarget(req.URL.RequestURI()),
+					semconv.NetHostName(req.Host),
+					attribute.String("http.client_ip", c.RealIP()),
+				),
+			)
+			defer span.End()
+
+			c.SetRequest(req.WithContext(ctx))
+			propagator.Inject(ctx, propagation.HeaderCarrier(c.Response().Header()))
+
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+
+			// The route template is only known after routing
+			span.SetName(req.Method + " " + c.Path())
+			span.SetAttributes(
+				semconv.HTTPRoute(c.Path()),
+				semconv.HTTPStatusCode(c.Response().Status),
+			)
+			if c.Response().Status >= 500 {
+				span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", c.Response().Status))
+			}
+			return nil
+		}
+	}
+}
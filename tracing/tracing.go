@@ -0,0 +1,92 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lucasfloriani/go-mongo/app"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentation is the name of the tracer used by the application
+const instrumentation = "github.com/lucasfloriani/go-mongo"
+
+// Init configures the tracer provider with the exporter of app.Config.Tracing and the W3C
+// propagator. The provider is flushed and stopped on shutdown. When tracing is disabled the
+// spans are still created, but not recorded.
+func Init() error {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	config := app.Config.Tracing
+	if !config.Enabled {
+		return nil
+	}
+
+	exporter, err := newExporter()
+	if err != nil {
+		return fmt.Errorf("Failed to create the trace exporter: %s", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(config.ServiceName),
+			semconv.ServiceVersion(app.Version),
+			semconv.DeploymentEnvironment(app.Config.Environment),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+	app.OnShutdown("tracing", provider.Shutdown)
+	return nil
+}
+
+// newExporter creates the exporter selected in config
+func newExporter() (sdktrace.SpanExporter, error) {
+	config := app.Config.Tracing
+	switch config.Exporter {
+	case "stdout":
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case "file":
+		file, err := os.OpenFile(config.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		app.OnShutdown("tracing file", func(context.Context) error { return file.Close() })
+		return stdouttrace.New(stdouttrace.WithWriter(io.Writer(file)))
+	default:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(config.Endpoint)}
+		if config.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(context.Background(), opts...)
+	}
+}
+
+// Start starts a span with the given name as a child of the span in the context.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentation).Start(ctx, name, opts...)
+}
+
+// End records the error, if any, in the span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}